@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
+	"github.com/priyanshujain/claude-coding/internal/converter"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+// bundle holds what is published next to the HTML pages of a session: a
+// Markdown rendering, the normalized JSON and, if requested, the raw JSONL
+// with paths, usernames, hostnames, emails and git remotes redacted.
+type bundle struct {
+	sessionID string
+	raw       string
+}
+
+func loadBundle(sessionID, sessionFile string, raw bool, a *anonymize.Anonymizer) (*bundle, error) {
+	b := &bundle{sessionID: sessionID}
+	if raw {
+		data, err := os.ReadFile(sessionFile)
+		if err != nil {
+			return nil, err
+		}
+		b.raw = a.String(string(data))
+	}
+	return b, nil
+}
+
+func (b *bundle) files(messages []parser.Message, cfg converter.Config) []publish.File {
+	files := []publish.File{
+		{Name: bundleFilename(b.sessionID, ".md"), Content: converter.Markdown(messages, cfg)},
+		{Name: bundleFilename(b.sessionID, ".json"), Content: converter.JSON(messages, cfg)},
+	}
+	if b.raw != "" {
+		files = append(files, publish.File{Name: bundleFilename(b.sessionID, ".jsonl"), Content: b.raw})
+	}
+	return files
+}
+
+func bundleFilename(sessionID, ext string) string {
+	return "claude-code-" + sessionID + ext
+}
@@ -0,0 +1,377 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+func chainCmd(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		chainShowCmd(args)
+		return
+	}
+
+	switch args[0] {
+	case "check":
+		chainCheckCmd(args[1:])
+	case "repair":
+		chainRepairCmd(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown chain command: %s\n", args[0])
+		printChainUsage()
+		os.Exit(1)
+	}
+}
+
+func printChainUsage() {
+	fmt.Fprintf(os.Stderr, "usage: claude-coding chain [check | repair] [options]\n")
+}
+
+// chainNode is a session in the printed history, with the sessions started
+// from it.
+type chainNode struct {
+	SessionID string       `json:"session_id"`
+	Kind      string       `json:"kind,omitempty"`
+	Title     string       `json:"title,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	Start     *time.Time   `json:"start,omitempty"`
+	End       *time.Time   `json:"end,omitempty"`
+	Messages  int          `json:"messages"`
+	Missing   bool         `json:"missing,omitempty"`
+	URLs      []string     `json:"urls,omitempty"`
+	Children  []*chainNode `json:"children,omitempty"`
+	// SeeAbove marks a session with several parents under all but the
+	// first, where it is printed in full.
+	SeeAbove bool `json:"see_above,omitempty"`
+}
+
+func chainShowCmd(args []string) {
+	fs := flag.NewFlagSet("chain", flag.ExitOnError)
+
+	var projectPath string
+	var sessionID string
+	var all bool
+	var jsonOutput bool
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.StringVar(&sessionID, "session", "", "only show the sessions linked to this one")
+	fs.BoolVar(&all, "all", false, "also show sessions that are not linked to any other")
+	fs.BoolVar(&jsonOutput, "json", false, "print the history as JSON")
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	m, err := metadata.LoadMetadata(projectPath)
+	if err != nil && !errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var trees []*chainNode
+	if sessionID != "" {
+		trees = chainTrees(projectPath, m, m.Family(sessionID), cfg)
+	} else {
+		for _, group := range m.Groups() {
+			if len(group) == 1 && !all {
+				continue
+			}
+			trees = append(trees, chainTrees(projectPath, m, m.Family(group[0]), cfg)...)
+		}
+	}
+	sort.SliceStable(trees, func(i, j int) bool {
+		return startTime(trees[i]).Before(startTime(trees[j]))
+	})
+
+	if jsonOutput {
+		if trees == nil {
+			trees = []*chainNode{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(trees)
+		return
+	}
+
+	if len(trees) == 0 {
+		fmt.Println("No linked sessions found")
+		return
+	}
+	for i, tree := range trees {
+		if i > 0 {
+			fmt.Println()
+		}
+		printChainNode(tree, "", "", "")
+	}
+}
+
+// chainTrees arranges the sessions of a family under their first parents.
+// A session with several parents is printed in full under the first one
+// and referred to under the others.
+func chainTrees(projectPath string, m *metadata.Metadata, family []string, cfg *config.Config) []*chainNode {
+	projectDir, _ := metadata.ProjectDir(projectPath)
+	members := make(map[string]bool, len(family))
+	for _, id := range family {
+		members[id] = true
+	}
+
+	placed := make(map[string]bool)
+	var build func(id, kind string) *chainNode
+	build = func(id, kind string) *chainNode {
+		node := &chainNode{SessionID: id, Kind: kind}
+		if placed[id] {
+			node.SeeAbove = true
+			return node
+		}
+		placed[id] = true
+		fillChainNode(node, filepath.Join(projectDir, id+".jsonl"), m, cfg)
+		for _, child := range m.GetChildren(id) {
+			if members[child.SessionID] {
+				node.Children = append(node.Children, build(child.SessionID, child.Kind))
+			}
+		}
+		return node
+	}
+
+	var trees []*chainNode
+	for _, id := range family {
+		if prev := m.GetPrevSessionID(id); !placed[id] && (prev == "" || !members[prev]) {
+			trees = append(trees, build(id, ""))
+		}
+	}
+	// Sessions only reachable through a loop or a one-sided link.
+	for _, id := range family {
+		if !placed[id] {
+			trees = append(trees, build(id, m.LinkKind(id)))
+		}
+	}
+	return trees
+}
+
+func fillChainNode(node *chainNode, sessionFile string, m *metadata.Metadata, cfg *config.Config) {
+	for _, key := range sortedKeys(m.GetShares(node.SessionID)) {
+		if url := shareURL(key, m.GetShares(node.SessionID)[key], node.SessionID, cfg); url != "" {
+			node.URLs = append(node.URLs, url)
+		}
+	}
+
+	messages, err := parser.ParseFile(sessionFile)
+	if err != nil {
+		node.Missing = true
+		return
+	}
+	node.Messages = len(messages)
+	node.Title = sessionTitle(m, node.SessionID, sessionFile, messages)
+	node.Tags = m.GetTags(node.SessionID)
+	for _, msg := range messages {
+		if msg.Timestamp.IsZero() {
+			continue
+		}
+		ts := msg.Timestamp
+		if node.Start == nil || ts.Before(*node.Start) {
+			node.Start = &ts
+		}
+		if node.End == nil || ts.After(*node.End) {
+			node.End = &ts
+		}
+	}
+}
+
+func printChainNode(node *chainNode, prefix, branch, indent string) {
+	label := node.SessionID
+	if node.Kind != "" && node.Kind != metadata.LinkClear {
+		label = "[" + node.Kind + "] " + label
+	}
+	switch {
+	case node.SeeAbove:
+		fmt.Printf("%s%s%s (see above)\n", prefix, branch, label)
+		return
+	case node.Missing:
+		fmt.Printf("%s%s%s (session file missing)\n", prefix, branch, label)
+	default:
+		fmt.Printf("%s%s%s  %s\n", prefix, branch, label, node.Title)
+	}
+
+	details := prefix + indent
+	if len(node.Children) > 0 {
+		details += "│  "
+	} else {
+		details += "   "
+	}
+	if !node.Missing {
+		info := fmt.Sprintf("%s · %d messages", timeRange(node.Start, node.End), node.Messages)
+		if len(node.Tags) > 0 {
+			info += " · " + strings.Join(node.Tags, ", ")
+		}
+		fmt.Printf("%s%s\n", details, info)
+	}
+	for _, url := range node.URLs {
+		fmt.Printf("%s%s\n", details, url)
+	}
+
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			printChainNode(child, prefix+indent, "└─ ", "   ")
+		} else {
+			printChainNode(child, prefix+indent, "├─ ", "│  ")
+		}
+	}
+}
+
+func timeRange(start, end *time.Time) string {
+	if start == nil {
+		return "no timestamps"
+	}
+	from := start.Local().Format("2006-01-02 15:04")
+	to := end.Local().Format("2006-01-02 15:04")
+	if start.Local().Format("2006-01-02") == end.Local().Format("2006-01-02") {
+		to = end.Local().Format("15:04")
+	}
+	return from + " → " + to
+}
+
+func startTime(node *chainNode) time.Time {
+	if node.Start == nil {
+		return time.Time{}
+	}
+	return *node.Start
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func chainCheckCmd(args []string) {
+	fs := flag.NewFlagSet("chain check", flag.ExitOnError)
+
+	var projectPath string
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	files, err := sessionFiles(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	m, err := metadata.LoadMetadata(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	problems := m.Check(files)
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+	for _, p := range problems {
+		fmt.Printf("%-15s %s\n", p.Kind, p.Message)
+	}
+	fmt.Printf("\n%d problem(s) found; run 'claude-coding chain repair' to fix them\n", len(problems))
+	os.Exit(1)
+}
+
+func chainRepairCmd(args []string) {
+	fs := flag.NewFlagSet("chain repair", flag.ExitOnError)
+
+	var projectPath string
+	var dryRun bool
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.BoolVar(&dryRun, "dry-run", false, "show the fixes without saving them")
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	files, err := sessionFiles(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var fixes []metadata.Fix
+	if dryRun {
+		m, err := metadata.LoadMetadata(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fixes = m.Repair(files)
+	} else {
+		err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+			fixes = m.Repair(files)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(fixes) == 0 {
+		fmt.Println("Nothing to repair")
+		return
+	}
+	for _, f := range fixes {
+		fmt.Println(f)
+	}
+	if dryRun {
+		fmt.Printf("\n%d link(s) would change\n", len(fixes))
+		return
+	}
+	fmt.Printf("\nRepaired %d link(s); re-share the affected sessions to update their published navigation\n", len(fixes))
+}
+
+func resolveProjectPath(projectPath string) string {
+	if projectPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		projectPath = cwd
+	}
+
+	projectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	return projectPath
+}
+
+// sessionFiles looks up the JSONL logs of the project's sessions.
+func sessionFiles(projectPath string) (metadata.SessionFiles, error) {
+	projectDir, err := metadata.ProjectDir(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	return func(sessionID string) (time.Time, bool) {
+		info, err := os.Stat(filepath.Join(projectDir, sessionID+".jsonl"))
+		if err != nil {
+			return time.Time{}, false
+		}
+		return info.ModTime(), true
+	}, nil
+}
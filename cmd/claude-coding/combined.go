@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
+	"github.com/priyanshujain/claude-coding/internal/converter"
+	"github.com/priyanshujain/claude-coding/internal/encrypt"
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+// combinedOptions are the share flags that apply to a combined export.
+type combinedOptions struct {
+	Title      string
+	Username   string
+	OutputPath string
+	Anonymize  bool
+	Encrypt    bool
+	Passphrase string
+	Publish    bool
+	Publisher  string
+	Host       string
+	Visibility string
+}
+
+func combinedFilename(sessionID string, page int) string {
+	if page <= 1 {
+		return "claude-code-chain-" + sessionID + ".html"
+	}
+	return fmt.Sprintf("claude-code-chain-%s.p%d.html", sessionID, page)
+}
+
+// shareCombined exports the chain of sessionID as one document, written to
+// the output path or published. A published export is recorded on the
+// first session of the chain and updated in place on the next run.
+func shareCombined(projectPath, sessionID string, m *metadata.Metadata, o combinedOptions) {
+	chain := m.Chain(sessionID)
+
+	anonymizeChain := o.Anonymize
+	encryptChain := o.Encrypt
+	var sections []converter.ChainSection
+	for _, id := range chain {
+		sessionFile, err := parser.GetSessionFilePath(projectPath, id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping session %s: %v\n", id, err)
+			continue
+		}
+		messages, err := parser.ParseFile(sessionFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping session %s: %v\n", id, err)
+			continue
+		}
+		if len(messages) == 0 {
+			continue
+		}
+		title := sessionTitle(m, id, sessionFile, messages)
+		kind := ""
+		if len(sections) > 0 {
+			kind = m.LinkKind(id)
+		}
+		sections = append(sections, converter.ChainSection{SessionID: id, Title: title, Kind: kind, Note: m.GetNote(id), Messages: messages})
+		// One anonymized or encrypted session anonymizes or encrypts
+		// the whole document, since the others would reveal what it hides.
+		anonymizeChain = anonymizeChain || m.IsAnonymized(id)
+		encryptChain = encryptChain || m.IsEncrypted(id)
+	}
+	if len(sections) == 0 {
+		fmt.Fprintf(os.Stderr, "error: no messages found in the chain\n")
+		os.Exit(1)
+	}
+
+	title := o.Title
+	if title == "" {
+		title = sections[0].Title
+	}
+	username := o.Username
+	displayPath := projectPath
+	if anonymizeChain {
+		a := anonymize.New()
+		for i := range sections {
+			sections[i].Messages = a.Messages(sections[i].Messages)
+			sections[i].Title = a.String(sections[i].Title)
+			sections[i].Note = a.String(sections[i].Note)
+		}
+		title = a.String(title)
+		displayPath = a.String(projectPath)
+		if username == "" {
+			username = a.Author
+		}
+	}
+	if username == "" {
+		username = getSystemUsername()
+	}
+
+	if encryptChain && o.Passphrase == "" {
+		fmt.Fprintf(os.Stderr, "error: %v\n", errMissingPassphrase)
+		os.Exit(1)
+	}
+
+	cfg := converter.Config{
+		Title:        title,
+		Username:     username,
+		UserInitials: getInitials(username),
+		ProjectPath:  displayPath,
+	}
+
+	if !o.Publish {
+		page := converter.ConvertChain(sections, cfg, 0)[0]
+		if encryptChain {
+			var err error
+			page, err = encrypt.HTML(page, o.Passphrase)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error encrypting thread: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := os.WriteFile(o.OutputPath, []byte(page), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		absOutput, _ := filepath.Abs(o.OutputPath)
+		fmt.Printf("Chain of %d sessions exported to: %s\n", len(sections), absOutput)
+		return
+	}
+
+	// The first session anchors the export, so it keeps its URL as the
+	// chain grows.
+	first := sections[0].SessionID
+	backend, host, cfgFile, err := resolveBackend(o.Publisher, o.Host)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	publisher, err := newPublisher(backend, host, cfgFile)
+	if err != nil {
+		exitPublishError("cannot publish", err)
+	}
+	if err := checkAuth(publisher); err != nil {
+		exitPublishError("cannot publish", err)
+	}
+	key := shareKey(backend, host)
+	previous := m.GetCombined(first, key)
+
+	// A new export starts with the visibility of the first session's
+	// share; exports made before it was recorded are secret.
+	recorded, err := gist.ParseVisibility(previous.Visibility)
+	if err != nil {
+		recorded = gist.Secret
+		if previous.ID == "" {
+			recorded = sessionVisibility(m, first, key, gist.Secret)
+		}
+	}
+	visibility := recorded
+	if o.Visibility != "" {
+		visibility, err = gist.ParseVisibility(o.Visibility)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	pub := publisherWithVisibility(publisher, visibility)
+	render := func(shareID string) []publish.File {
+		cfg := cfg
+		if shareID != "" {
+			cfg.PageURL = func(page int) string {
+				return publish.Link(publisher, shareID, combinedFilename(first, page))
+			}
+		}
+		pages := converter.ConvertChain(sections, cfg, pageBytes(encryptChain))
+		files := make([]publish.File, len(pages))
+		for i, page := range pages {
+			if encryptChain {
+				encrypted, err := encrypt.HTML(page, o.Passphrase)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "error encrypting thread: %v\n", err)
+					os.Exit(1)
+				}
+				page = encrypted
+			}
+			files[i] = publish.File{Name: combinedFilename(first, i+1), Content: page}
+		}
+		return files
+	}
+
+	// A gist's visibility cannot be changed in place, so the export moves
+	// to a new gist and the old one is deleted once the new one is
+	// recorded.
+	shareID := previous.ID
+	replaced := ""
+	if shareID != "" && backend == publish.BackendGist && visibility != recorded {
+		fmt.Fprintf(os.Stderr, "warning: gist visibility cannot be changed in place, creating a new %s gist\n", visibility)
+		replaced, shareID = shareID, ""
+	}
+	files := render(shareID)
+	if shareID != "" {
+		err = pub.Update(shareID, files, previous.Files)
+		if publish.IsNotFound(err) {
+			shareID = ""
+			files = render("")
+		} else if err != nil {
+			exitPublishError("failed to publish chain", err)
+		}
+	}
+	if shareID == "" {
+		if shareID, err = pub.Publish(files); err != nil {
+			exitPublishError("failed to publish chain", err)
+		}
+		// Links between the pages need the new ID.
+		if len(files) > 1 {
+			files = render(shareID)
+			if err := pub.Update(shareID, files, fileNames(files)); err != nil {
+				exitPublishError("failed to publish chain", err)
+			}
+		}
+	}
+
+	combined := metadata.Combined{ID: shareID, Files: fileNames(files)}
+	if backend == publish.BackendGist {
+		combined.Visibility = string(visibility)
+	}
+	err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+		m.SetCombined(first, key, combined)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(publisher.URL(shareID, combinedFilename(first, 1)))
+
+	if replaced != "" {
+		if err := publisher.Delete(replaced, previous.Files); err != nil && !publish.IsNotFound(err) {
+			oldURL := publisher.URL(replaced, combinedFilename(first, 1))
+			exitPublishError("failed to delete the previous gist "+oldURL+", delete it by hand", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/internal/preview"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+func configCmd(args []string) {
+	c, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		for _, key := range c.Keys() {
+			value, _ := c.Get(key)
+			fmt.Printf("%s=%s\n", key, value)
+		}
+		return
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 2 {
+			fmt.Fprintf(os.Stderr, "usage: claude-coding config get <key>\n")
+			os.Exit(1)
+		}
+		value, err := c.Get(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+		return
+
+	case "set", "unset":
+		var key, value string
+		switch {
+		case args[0] == "set" && len(args) == 3:
+			key, value = args[1], args[2]
+		case args[0] == "unset" && len(args) == 2:
+			key = args[1]
+		default:
+			fmt.Fprintf(os.Stderr, "usage: claude-coding config set <key> <value> | unset <key>\n")
+			os.Exit(1)
+		}
+
+		if key == "publisher" && value != "" {
+			if _, err := publish.ParseBackend(value); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := c.Set(key, value); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		if (key == "preview.provider" || key == "preview.url") && (c.Preview.Provider != "" || c.Preview.URL != "") {
+			if _, err := preview.Template(c.Preview.Provider, c.Preview.URL); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := config.Save(c); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown config command: %s\n", args[0])
+	fmt.Fprintf(os.Stderr, "usage: claude-coding config [list | get <key> | set <key> <value> | unset <key>]\n")
+	os.Exit(1)
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/index"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+const defaultGCMinAge = 24 * time.Hour
+
+type gcDrop struct {
+	SessionID string
+	Reason    string
+	// File is the session file as gc found it, nil if it was gone.
+	File os.FileInfo
+}
+
+// gcOrphan is a share that is still online although its session file is
+// gone, so it can no longer be re-rendered.
+type gcOrphan struct {
+	SessionID string
+	Publisher string
+	ID        string
+	URL       string
+}
+
+func gcCmd(args []string) {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+
+	var projectPath string
+	var all bool
+	var dryRun bool
+	var minAge time.Duration
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.BoolVar(&all, "all", false, "clean up every project under ~/.claude/projects")
+	fs.BoolVar(&dryRun, "dry-run", false, "show what would be removed without saving")
+	fs.DurationVar(&minAge, "min-age", defaultGCMinAge, "leave entries changed more recently than this alone")
+	fs.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var projectDirs []string
+	if all {
+		projectsDir, err := metadata.ProjectsDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		entries, err := os.ReadDir(projectsDir)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				projectDirs = append(projectDirs, filepath.Join(projectsDir, entry.Name()))
+			}
+		}
+	} else {
+		projectDir, err := metadata.ProjectDir(resolveProjectPath(projectPath))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		projectDirs = []string{projectDir}
+	}
+
+	var dropped, kept int
+	var removed []string
+	var orphans []gcOrphan
+	failed := false
+	for _, projectDir := range projectDirs {
+		snapshot, err := metadata.LoadMetadataDir(projectDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %s: %v\n", projectDir, err)
+			failed = true
+			continue
+		}
+		// Session files are parsed without holding the metadata lock, so
+		// hooks are not kept waiting; each pick is checked again under the
+		// lock before it is removed.
+		drops, projectOrphans := collectGarbage(projectDir, snapshot, minAge, cfg)
+		remaining := len(snapshot.Sessions) - len(drops)
+		if !dryRun && len(drops) > 0 {
+			picked := drops
+			err = metadata.WithLockDir(projectDir, func(m *metadata.Metadata) error {
+				drops = nil
+				for _, d := range picked {
+					if unchanged(projectDir, m, snapshot, d) {
+						m.RemoveSession(d.SessionID)
+						drops = append(drops, d)
+					}
+				}
+				remaining = len(m.Sessions)
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %s: %v\n", projectDir, err)
+				failed = true
+				continue
+			}
+		}
+
+		if all && len(drops) > 0 {
+			fmt.Printf("%s:\n", projectDir)
+		}
+		for _, d := range drops {
+			fmt.Printf("  %s  %s\n", d.SessionID, d.Reason)
+		}
+		for _, d := range drops {
+			removed = append(removed, d.SessionID)
+		}
+		dropped += len(drops)
+		kept += remaining
+		orphans = append(orphans, projectOrphans...)
+	}
+
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	} else if len(removed) > 0 {
+		if err := index.Remove(removed...); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: updating session index: %v\n", err)
+		}
+	}
+	fmt.Printf("%s %d entries, %d left\n", verb, dropped, kept)
+
+	if len(orphans) > 0 {
+		fmt.Printf("\nShares whose session file is gone (kept so they can be unshared):\n")
+		for _, o := range orphans {
+			fmt.Printf("  %s  %s  %s\n", o.SessionID, o.Publisher, o.URL)
+		}
+		fmt.Printf("\nDelete a thread with 'claude-coding unshare --session <id>' in its project, then run gc again\n")
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// collectGarbage picks the entries gc removes: those whose session file is
+// gone and those whose session has no messages, unless they were shared or
+// labelled. It also returns the shares of sessions whose file is gone.
+func collectGarbage(projectDir string, m *metadata.Metadata, minAge time.Duration, cfg *config.Config) ([]gcDrop, []gcOrphan) {
+	ids := make([]string, 0, len(m.Sessions))
+	for id := range m.Sessions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var drops []gcDrop
+	var orphans []gcOrphan
+	for _, id := range ids {
+		s := m.Sessions[id]
+		// A session that just started may not have written its file yet.
+		if time.Since(s.UpdatedAt) < minAge {
+			continue
+		}
+		shares := m.GetShares(id)
+		sessionFile := filepath.Join(projectDir, id+".jsonl")
+
+		info, err := os.Stat(sessionFile)
+		if os.IsNotExist(err) {
+			if len(shares) == 0 && len(s.Combined) == 0 {
+				drops = append(drops, gcDrop{SessionID: id, Reason: "session file is gone"})
+				continue
+			}
+			for _, key := range sortedKeys(shares) {
+				orphans = append(orphans, gcOrphan{SessionID: id, Publisher: key, ID: shares[key], URL: shareURL(key, shares[key], id, cfg)})
+			}
+			for _, key := range sortedCombinedKeys(s.Combined) {
+				c := s.Combined[key]
+				orphans = append(orphans, gcOrphan{SessionID: id, Publisher: key + " (chain)", ID: c.ID, URL: publishedURL(key, c.ID, combinedFilename(id, 1), cfg)})
+			}
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if len(shares) > 0 || len(s.Combined) > 0 || len(s.Unshared) > 0 || s.Title != "" || s.Note != "" || len(s.Tags) > 0 {
+			continue
+		}
+		if info.Size() > 0 {
+			if messages, err := parser.ParseFile(sessionFile); err != nil || len(messages) > 0 {
+				continue
+			}
+		}
+		drops = append(drops, gcDrop{SessionID: id, Reason: "empty and never shared", File: info})
+	}
+	return drops, orphans
+}
+
+// unchanged reports whether a session gc picked from a snapshot of the
+// metadata is still as it was, both in the metadata and on disk.
+func unchanged(projectDir string, m, snapshot *metadata.Metadata, d gcDrop) bool {
+	s, ok := m.Sessions[d.SessionID]
+	if !ok || !reflect.DeepEqual(s, snapshot.Sessions[d.SessionID]) {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(projectDir, d.SessionID+".jsonl"))
+	if d.File == nil {
+		return os.IsNotExist(err)
+	}
+	return err == nil && info.Size() == d.File.Size() && info.ModTime().Equal(d.File.ModTime())
+}
+
+func sortedCombinedKeys(m map[string]metadata.Combined) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
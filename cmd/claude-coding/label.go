@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+func tagCmd(args []string) {
+	fs := flag.NewFlagSet("tag", flag.ExitOnError)
+
+	var projectPath string
+	var sessionID string
+	var remove bool
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.StringVar(&sessionID, "session", "", "session ID (default $CLAUDE_SESSION_ID, else the latest session)")
+	fs.BoolVar(&remove, "remove", false, "remove the tags instead of adding them")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: claude-coding tag [options] [tag ...]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	sessionID = labelSession(projectPath, sessionID)
+
+	var tags []string
+	for _, arg := range fs.Args() {
+		for _, tag := range strings.Split(arg, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	if len(tags) == 0 {
+		if remove {
+			fs.Usage()
+			os.Exit(1)
+		}
+		m := loadLabels(projectPath)
+		for _, tag := range m.GetTags(sessionID) {
+			fmt.Println(tag)
+		}
+		return
+	}
+
+	updateLabels(projectPath, sessionID, func(m *metadata.Metadata) {
+		if remove {
+			m.RemoveTags(sessionID, tags...)
+		} else {
+			m.AddTags(sessionID, tags...)
+		}
+	})
+}
+
+func titleCmd(args []string) {
+	fs := flag.NewFlagSet("title", flag.ExitOnError)
+
+	var projectPath string
+	var sessionID string
+	var clearLabel bool
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.StringVar(&sessionID, "session", "", "session ID (default $CLAUDE_SESSION_ID, else the latest session)")
+	fs.BoolVar(&clearLabel, "clear", false, "remove the title and use the one derived from the session again")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: claude-coding title [options] [title]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	sessionID = labelSession(projectPath, sessionID)
+	title := strings.TrimSpace(strings.Join(fs.Args(), " "))
+
+	if title == "" && !clearLabel {
+		fmt.Println(loadLabels(projectPath).GetTitle(sessionID))
+		return
+	}
+	updateLabels(projectPath, sessionID, func(m *metadata.Metadata) {
+		m.SetTitle(sessionID, title)
+	})
+}
+
+func noteCmd(args []string) {
+	fs := flag.NewFlagSet("note", flag.ExitOnError)
+
+	var projectPath string
+	var sessionID string
+	var clearLabel bool
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.StringVar(&sessionID, "session", "", "session ID (default $CLAUDE_SESSION_ID, else the latest session)")
+	fs.BoolVar(&clearLabel, "clear", false, "remove the note")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: claude-coding note [options] [text | -]\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	projectPath = resolveProjectPath(projectPath)
+	sessionID = labelSession(projectPath, sessionID)
+
+	note := strings.Join(fs.Args(), " ")
+	if note == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		note = string(data)
+	}
+	note = strings.TrimSpace(note)
+
+	if note == "" && !clearLabel {
+		fmt.Println(loadLabels(projectPath).GetNote(sessionID))
+		return
+	}
+	updateLabels(projectPath, sessionID, func(m *metadata.Metadata) {
+		m.SetNote(sessionID, note)
+	})
+}
+
+// labelSession picks the session a label command applies to: the one
+// given, the one the command runs in, or the latest session of the project.
+// Unlike share, it does not move on to later sessions in the chain.
+func labelSession(projectPath, sessionID string) string {
+	if sessionID == "" {
+		sessionID = os.Getenv("CLAUDE_SESSION_ID")
+	}
+	if sessionID == "" {
+		var err error
+		sessionID, err = parser.FindLatestSessionID(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error finding session: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	return sessionID
+}
+
+func loadLabels(projectPath string) *metadata.Metadata {
+	m, err := metadata.LoadMetadata(projectPath)
+	if err != nil && !errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	return m
+}
+
+// updateLabels applies fn to the metadata and reminds the user that
+// published threads only pick up the change when shared again.
+func updateLabels(projectPath, sessionID string, fn func(m *metadata.Metadata)) {
+	shared := false
+	err := metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+		fn(m)
+		for _, id := range m.Family(sessionID) {
+			shared = shared || len(m.GetShares(id)) > 0
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+		os.Exit(1)
+	}
+	updateIndex(projectPath, sessionID)
+	if shared {
+		fmt.Println("Share the session again to update its published thread")
+	}
+}
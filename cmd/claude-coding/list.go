@@ -0,0 +1,347 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/index"
+)
+
+// sessionRow is an indexed session as printed by list and search.
+type sessionRow struct {
+	metadata.IndexEntry
+	URLs []string `json:"urls,omitempty"`
+}
+
+// sessionFilter holds the options list, search and stats share.
+type sessionFilter struct {
+	project string
+	tag     string
+	shared  bool
+	since   time.Duration
+}
+
+func (f *sessionFilter) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.project, "project", "", "only sessions of this project (default every project)")
+	fs.StringVar(&f.tag, "tag", "", "only sessions with this tag")
+	fs.BoolVar(&f.shared, "shared", false, "only shared sessions")
+	fs.DurationVar(&f.since, "since", 0, "only sessions active within this duration, e.g. 168h")
+}
+
+func (f *sessionFilter) apply(entries []metadata.IndexEntry) []metadata.IndexEntry {
+	projectDir := ""
+	if f.project != "" {
+		dir, err := metadata.ProjectDir(resolveProjectPath(f.project))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		projectDir = filepath.Base(dir)
+	}
+
+	var matched []metadata.IndexEntry
+	for _, e := range entries {
+		if projectDir != "" && e.ProjectDir != projectDir {
+			continue
+		}
+		if f.tag != "" && !slices.Contains(e.Tags, f.tag) {
+			continue
+		}
+		if f.shared && len(e.Shares) == 0 {
+			continue
+		}
+		if f.since > 0 && time.Since(e.LastActive()) > f.since {
+			continue
+		}
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+func listCmd(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+
+	var filter sessionFilter
+	var limit int
+	var format string
+
+	filter.register(fs)
+	fs.IntVar(&limit, "limit", 50, "show at most this many sessions, 0 for all")
+	fs.StringVar(&format, "format", "table", "output format: table, json or csv")
+	fs.Parse(args)
+
+	entries := filter.apply(loadIndex().Entries())
+	printSessions(entries, limit, format)
+}
+
+func searchCmd(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+
+	var filter sessionFilter
+	var limit int
+	var format string
+
+	filter.register(fs)
+	fs.IntVar(&limit, "limit", 50, "show at most this many sessions, 0 for all")
+	fs.StringVar(&format, "format", "table", "output format: table, json or csv")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: claude-coding search [options] <words>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	words := strings.Fields(strings.ToLower(strings.Join(fs.Args(), " ")))
+	if len(words) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var matched []metadata.IndexEntry
+	for _, e := range filter.apply(loadIndex().Entries()) {
+		text := strings.ToLower(strings.Join([]string{e.SessionID, e.ProjectPath, e.Title, e.Summary, e.Note, strings.Join(e.Tags, " ")}, "\n"))
+		found := true
+		for _, word := range words {
+			found = found && strings.Contains(text, word)
+		}
+		if found {
+			matched = append(matched, e)
+		}
+	}
+	printSessions(matched, limit, format)
+}
+
+func printSessions(entries []metadata.IndexEntry, limit int, format string) {
+	if format != "table" && format != "json" && format != "csv" {
+		fmt.Fprintf(os.Stderr, "error: invalid format %q (want table, json or csv)\n", format)
+		os.Exit(1)
+	}
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	rows := make([]sessionRow, len(entries))
+	for i, e := range entries {
+		rows[i] = sessionRow{IndexEntry: e}
+		for _, key := range sortedKeys(e.Shares) {
+			if url := shareURL(key, e.Shares[key], e.SessionID, cfg); url != "" {
+				rows[i].URLs = append(rows[i].URLs, url)
+			}
+		}
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"project", "session_id", "title", "start", "end", "messages", "prompts", "tool_calls", "tags", "urls"})
+		for _, r := range rows {
+			w.Write([]string{r.ProjectPath, r.SessionID, r.DisplayTitle(), formatTime(r.Start), formatTime(r.End), strconv.Itoa(r.Messages), strconv.Itoa(r.Prompts), strconv.Itoa(r.ToolCalls), strings.Join(r.Tags, ";"), strings.Join(r.URLs, ";")})
+		}
+		w.Flush()
+	default:
+		if len(rows) == 0 {
+			fmt.Println("No sessions found")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTIVE\tPROJECT\tSESSION\tMESSAGES\tSHARED\tTITLE")
+		for _, r := range rows {
+			title := r.DisplayTitle()
+			if len(title) > 50 {
+				title = title[:47] + "..."
+			}
+			shared := ""
+			if len(r.URLs) > 0 {
+				shared = "yes"
+			}
+			session := r.SessionID
+			if len(session) > 8 {
+				session = session[:8]
+			}
+			if r.Missing {
+				session += " (gone)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", r.LastActive().Local().Format("2006-01-02 15:04"), displayProject(r.IndexEntry), session, r.Messages, shared, title)
+		}
+		w.Flush()
+	}
+}
+
+type projectStats struct {
+	Project    string    `json:"project"`
+	Sessions   int       `json:"sessions"`
+	Messages   int       `json:"messages"`
+	Shared     int       `json:"shared"`
+	LastActive time.Time `json:"last_active"`
+}
+
+type sessionStats struct {
+	Sessions  int            `json:"sessions"`
+	Messages  int            `json:"messages"`
+	Prompts   int            `json:"prompts"`
+	ToolCalls int            `json:"tool_calls"`
+	Shared    int            `json:"shared"`
+	First     time.Time      `json:"first"`
+	Last      time.Time      `json:"last"`
+	Projects  []projectStats `json:"projects"`
+}
+
+func statsCmd(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+
+	var filter sessionFilter
+	var jsonOutput bool
+
+	filter.register(fs)
+	fs.BoolVar(&jsonOutput, "json", false, "print the statistics as JSON")
+	fs.Parse(args)
+
+	var stats sessionStats
+	projects := make(map[string]*projectStats)
+	for _, e := range filter.apply(loadIndex().Entries()) {
+		stats.Sessions++
+		stats.Messages += e.Messages
+		stats.Prompts += e.Prompts
+		stats.ToolCalls += e.ToolCalls
+		if !e.Start.IsZero() && (stats.First.IsZero() || e.Start.Before(stats.First)) {
+			stats.First = e.Start
+		}
+		if active := e.LastActive(); active.After(stats.Last) {
+			stats.Last = active
+		}
+
+		p := projects[e.ProjectDir]
+		if p == nil {
+			p = &projectStats{Project: displayProject(e)}
+			projects[e.ProjectDir] = p
+		}
+		p.Sessions++
+		p.Messages += e.Messages
+		if len(e.Shares) > 0 {
+			stats.Shared++
+			p.Shared++
+		}
+		if active := e.LastActive(); active.After(p.LastActive) {
+			p.LastActive = active
+		}
+	}
+	stats.Projects = []projectStats{}
+	for _, p := range projects {
+		stats.Projects = append(stats.Projects, *p)
+	}
+	sort.Slice(stats.Projects, func(i, j int) bool {
+		if stats.Projects[i].Sessions != stats.Projects[j].Sessions {
+			return stats.Projects[i].Sessions > stats.Projects[j].Sessions
+		}
+		return stats.Projects[i].Project < stats.Projects[j].Project
+	})
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(stats)
+		return
+	}
+
+	if stats.Sessions == 0 {
+		fmt.Println("No sessions found")
+		return
+	}
+	fmt.Printf("Sessions  %d in %d projects\n", stats.Sessions, len(stats.Projects))
+	fmt.Printf("Messages  %d (%d prompts, %d tool calls)\n", stats.Messages, stats.Prompts, stats.ToolCalls)
+	fmt.Printf("Shared    %d sessions\n", stats.Shared)
+	if !stats.First.IsZero() {
+		fmt.Printf("Active    %s → %s\n", stats.First.Local().Format("2006-01-02"), stats.Last.Local().Format("2006-01-02"))
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSESSIONS\tMESSAGES\tSHARED\tLAST ACTIVE")
+	for _, p := range stats.Projects {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", p.Project, p.Sessions, p.Messages, p.Shared, p.LastActive.Local().Format("2006-01-02"))
+	}
+	w.Flush()
+}
+
+func indexCmd(args []string) {
+	fs := flag.NewFlagSet("index", flag.ExitOnError)
+	fs.Parse(args)
+
+	sessions, projects, err := index.Rebuild()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Indexed %d sessions in %d projects\n", sessions, projects)
+}
+
+// loadIndex reads the global session index, building it on first use.
+func loadIndex() *metadata.Index {
+	path, err := metadata.IndexPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Building the session index, this only happens once...\n")
+		if _, _, err := index.Rebuild(); err != nil {
+			fmt.Fprintf(os.Stderr, "error building session index: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	idx, err := metadata.LoadIndex()
+	if err != nil && !errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "error: %v; run 'claude-coding index' to rebuild it\n", err)
+		os.Exit(1)
+	}
+	return idx
+}
+
+// updateIndex refreshes the index entries of sessions a command changed.
+// The index is only a cache, so a failure is a warning.
+func updateIndex(projectPath string, sessionIDs ...string) {
+	if err := index.Update(projectPath, sessionIDs...); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: updating session index: %v\n", err)
+	}
+}
+
+func displayProject(e metadata.IndexEntry) string {
+	if e.ProjectPath == "" {
+		return e.ProjectDir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, e.ProjectPath); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join("~", rel)
+		}
+	}
+	return e.ProjectPath
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -9,10 +10,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
 	"github.com/priyanshujain/claude-coding/internal/converter"
+	"github.com/priyanshujain/claude-coding/internal/encrypt"
 	"github.com/priyanshujain/claude-coding/internal/gist"
-	"github.com/priyanshujain/claude-coding/generic/metadata"
 	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
 )
 
 func main() {
@@ -24,6 +28,30 @@ func main() {
 	switch os.Args[1] {
 	case "share":
 		shareCmd(os.Args[2:])
+	case "shares":
+		sharesCmd(os.Args[2:])
+	case "unshare":
+		unshareCmd(os.Args[2:])
+	case "chain":
+		chainCmd(os.Args[2:])
+	case "tag":
+		tagCmd(os.Args[2:])
+	case "title":
+		titleCmd(os.Args[2:])
+	case "note":
+		noteCmd(os.Args[2:])
+	case "list":
+		listCmd(os.Args[2:])
+	case "search":
+		searchCmd(os.Args[2:])
+	case "stats":
+		statsCmd(os.Args[2:])
+	case "index":
+		indexCmd(os.Args[2:])
+	case "gc":
+		gcCmd(os.Args[2:])
+	case "config":
+		configCmd(os.Args[2:])
 	case "help", "-h", "--help":
 		printUsage()
 	default:
@@ -38,6 +66,18 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Commands:")
 	fmt.Println("  share    Export conversation thread to HTML")
+	fmt.Println("  shares   List shared threads for this project or all projects")
+	fmt.Println("  unshare  Delete a shared thread and relink its neighbours")
+	fmt.Println("  chain    Show, check and repair the links between sessions")
+	fmt.Println("  tag      Add, remove or list a session's tags")
+	fmt.Println("  title    Set the title a session is shared under")
+	fmt.Println("  note     Set an author's note shown at the top of a shared session")
+	fmt.Println("  list     List sessions across every project, newest first")
+	fmt.Println("  search   Find sessions by title, note, tag or project")
+	fmt.Println("  stats    Summarize sessions, messages and shares per project")
+	fmt.Println("  index    Rebuild the session index from every project")
+	fmt.Println("  gc       Remove metadata of deleted and empty sessions")
+	fmt.Println("  config   Show or change settings (publisher, backend options)")
 	fmt.Println()
 	fmt.Println("Run 'claude-coding <command> -h' for command-specific help")
 }
@@ -51,15 +91,43 @@ func shareCmd(args []string) {
 	var username string
 	var sessionID string
 	var createGist bool
+	var anonymizeThread bool
+	var encryptThread bool
+	var bundleThread bool
+	var rawJSONL bool
+	var passphrase string
+	var visibilityFlag string
+	var publishThread bool
+	var publisherName string
+	var host string
+	var jobs int
+	var chainExport bool
 
 	fs.StringVar(&projectPath, "project", "", "project path")
 	fs.StringVar(&outputPath, "output", "", "output file path")
 	fs.StringVar(&title, "title", "", "thread title")
 	fs.StringVar(&username, "username", "", "username to display")
 	fs.StringVar(&sessionID, "session", "", "specific session ID to export")
-	fs.BoolVar(&createGist, "gist", false, "create GitHub gist and return preview URL")
+	fs.BoolVar(&publishThread, "publish", false, "publish the thread and return its shareable URL")
+	fs.StringVar(&publisherName, "publisher", "", "publishing backend: gist, dir, s3, http or git-pages (default from config, else gist)")
+	fs.BoolVar(&createGist, "gist", false, "create GitHub gist and return preview URL (same as --publish)")
+	fs.BoolVar(&anonymizeThread, "anonymize", false, "rewrite paths, usernames, hostnames, emails and git remotes and hide the author")
+	fs.BoolVar(&encryptThread, "encrypt", false, "encrypt the thread with a passphrase; viewers decrypt it in the browser")
+	fs.BoolVar(&bundleThread, "bundle", false, "also publish a Markdown rendering and the normalized JSON of the thread")
+	fs.BoolVar(&rawJSONL, "raw", false, "also publish the session's raw JSONL with personal details redacted (implies --bundle)")
+	fs.StringVar(&passphrase, "passphrase", "", "passphrase for --encrypt (default $CLAUDE_CODING_PASSPHRASE)")
+	fs.StringVar(&host, "host", "", "GitHub host for gists, e.g. a GitHub Enterprise Server hostname (default from config, $GH_HOST, else github.com)")
+	fs.StringVar(&visibilityFlag, "visibility", "", "gist visibility: secret (default) or public")
+	fs.IntVar(&jobs, "jobs", defaultJobs, "number of linked sessions to upload in parallel")
+	fs.BoolVar(&chainExport, "chain", false, "export every session of the chain as one document with a table of contents")
 	fs.Parse(args)
 
+	publishThread = publishThread || createGist || publisherName != ""
+
+	if passphrase == "" {
+		passphrase = os.Getenv("CLAUDE_CODING_PASSPHRASE")
+	}
+
 	if projectPath == "" {
 		cwd, err := os.Getwd()
 		if err != nil {
@@ -81,9 +149,120 @@ func shareCmd(args []string) {
 		os.Exit(1)
 	}
 
-	m, _ := metadata.LoadMetadata(projectPath)
-	prevSessionID := m.GetPrevSessionID(sessionID)
-	nextSessionID := m.GetNextSessionID(sessionID)
+	m, err := metadata.LoadMetadata(projectPath)
+	if errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if chainExport {
+		if bundleThread || rawJSONL {
+			fmt.Fprintf(os.Stderr, "warning: --bundle and --raw do not apply to --chain, exporting the HTML only\n")
+		}
+		if outputPath == "" {
+			outputPath = fmt.Sprintf("./chain-%s.html", time.Now().Format("20060102-150405"))
+		}
+		shareCombined(projectPath, sessionID, m, combinedOptions{
+			Title:      title,
+			Username:   username,
+			OutputPath: outputPath,
+			Anonymize:  anonymizeThread,
+			Encrypt:    encryptThread,
+			Passphrase: passphrase,
+			Publish:    publishThread,
+			Publisher:  publisherName,
+			Host:       host,
+			Visibility: visibilityFlag,
+		})
+		return
+	}
+
+	if isFlagSet(fs, "anonymize") {
+		if publishThread && m.IsAnonymized(sessionID) != anonymizeThread {
+			err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+				m.SetAnonymized(sessionID, anonymizeThread)
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	} else {
+		anonymizeThread = m.IsAnonymized(sessionID)
+	}
+
+	if isFlagSet(fs, "encrypt") {
+		if publishThread && m.IsEncrypted(sessionID) != encryptThread {
+			err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+				m.SetEncrypted(sessionID, encryptThread)
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	} else {
+		encryptThread = m.IsEncrypted(sessionID)
+	}
+
+	if isFlagSet(fs, "raw") {
+		if publishThread && m.HasRawJSONL(sessionID) != rawJSONL {
+			err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+				m.SetRawJSONL(sessionID, rawJSONL)
+				return nil
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	} else {
+		rawJSONL = m.HasRawJSONL(sessionID)
+	}
+
+	if !isFlagSet(fs, "bundle") {
+		bundleThread = m.IsBundled(sessionID)
+	}
+	bundleThread = bundleThread || rawJSONL
+	if publishThread && m.IsBundled(sessionID) != bundleThread {
+		err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+			m.SetBundled(sessionID, bundleThread)
+			return nil
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if encryptThread && bundleThread {
+		fmt.Fprintf(os.Stderr, "warning: bundle files cannot be encrypted, publishing the HTML only\n")
+		bundleThread = false
+	}
+
+	if encryptThread && passphrase == "" {
+		fmt.Fprintf(os.Stderr, "error: %v\n", errMissingPassphrase)
+		os.Exit(1)
+	}
+
+	backend, host, cfgFile, err := resolveBackend(publisherName, host)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	key := shareKey(backend, host)
+	visibility := sessionVisibility(m, sessionID, key, gist.Secret)
+	if visibilityFlag != "" {
+		visibility, err = gist.ParseVisibility(visibilityFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	publisher, publisherErr := newPublisher(backend, host, cfgFile)
+	opts := syncOptions{Backend: backend, Key: key, Publisher: publisher, Passphrase: passphrase, Visibility: visibility, Jobs: jobs, Anonymizer: anonymize.New()}
 
 	if outputPath == "" {
 		outputPath = fmt.Sprintf("./thread-%s.html", time.Now().Format("20060102-150405"))
@@ -107,108 +286,128 @@ func shareCmd(args []string) {
 	}
 
 	if title == "" {
-		title = parser.ParseSummary(sessionFile)
-	}
-	if title == "" {
-		title = extractTitle(messages)
+		title = sessionTitle(m, sessionID, sessionFile, messages)
 	}
+	note := m.GetNote(sessionID)
 
+	usernameSet := username != ""
 	if username == "" {
 		username = getSystemUsername()
 	}
 
-	if createGist {
-		gistOK := true
-		if !gist.IsGHAvailable() {
-			fmt.Fprintf(os.Stderr, "warning: gh CLI is not installed, skipping gist creation\n")
-			gistOK = false
-		} else if !gist.IsGHAuthenticated() {
-			fmt.Fprintf(os.Stderr, "warning: gh CLI is not authenticated, skipping gist creation\n")
-			gistOK = false
+	displayPath := projectPath
+	if anonymizeThread {
+		a := opts.Anonymizer
+		messages = a.Messages(messages)
+		title = a.String(title)
+		note = a.String(note)
+		displayPath = a.String(projectPath)
+		if !usernameSet {
+			username = a.Author
 		}
+	}
 
-		if gistOK && sessionID != "" {
-			m, _ := metadata.LoadMetadata(projectPath)
-
-			ensureSessionGist(projectPath, m, prevSessionID)
-			ensureSessionGist(projectPath, m, nextSessionID)
-
-			m, _ = metadata.LoadMetadata(projectPath)
+	var extras *bundle
+	if bundleThread {
+		extras, err = loadBundle(sessionID, sessionFile, rawJSONL, opts.Anonymizer)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading session: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	threadConfig := func(nav sessionNav) converter.Config {
+		return nav.apply(converter.Config{
+			Title:        title,
+			Username:     username,
+			UserInitials: getInitials(username),
+			ProjectPath:  displayPath,
+			Note:         note,
+		})
+	}
 
-			var prevSessionURL, nextSessionURL string
-			if prevSessionID != "" {
-				if prevGistID := m.GetGistID(prevSessionID); prevGistID != "" {
-					prevSessionURL = gist.PreviewURL(prevGistID)
-				}
-			}
-			if nextSessionID != "" {
-				if nextGistID := m.GetGistID(nextSessionID); nextGistID != "" {
-					nextSessionURL = gist.PreviewURL(nextGistID)
-				}
-			}
+	if publishThread {
+		if publisherErr != nil {
+			exitPublishError("cannot publish", publisherErr)
+		}
+		if err := checkAuth(opts.Publisher); err != nil {
+			exitPublishError("cannot publish", err)
+		}
 
-			cfg := converter.Config{
-				Title:          title,
-				Username:       username,
-				UserInitials:   getInitials(username),
-				ProjectPath:    projectPath,
-				PrevSessionURL: prevSessionURL,
-				NextSessionURL: nextSessionURL,
-			}
-			html := converter.Convert(messages, cfg)
-
-			filename := "claude-code-" + sessionID + ".html"
-			var previewURL string
-			var gistID string
-
-			existingGistID := m.GetGistID(sessionID)
-			if existingGistID != "" {
-				previewURL, err = gist.Update(existingGistID, filename, html)
-				if err != nil {
-					gistID, previewURL, err = gist.Create(filename, html)
-				} else {
-					gistID = existingGistID
-				}
-			} else {
-				gistID, previewURL, err = gist.Create(filename, html)
+		m, _ := metadata.LoadMetadata(projectPath)
+		check := m.GetPassphraseCheck(sessionID)
+		if encryptThread && !encrypt.Verify(check, passphrase) {
+			if check, err = encrypt.NewVerifier(passphrase); err != nil {
+				fmt.Fprintf(os.Stderr, "error encrypting thread: %v\n", err)
+				os.Exit(1)
 			}
-
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to create/update gist: %v\n", err)
-			} else {
-				m.SetGistID(sessionID, gistID)
-				metadata.SaveMetadata(projectPath, m)
-				updateSessionChain(projectPath, m, sessionID, previewURL)
-				fmt.Println(previewURL)
-				return
+		}
+		current := &syncItem{
+			SessionID:       sessionID,
+			Title:           title,
+			Start:           startedAt(messages),
+			ShareID:         m.GetShareID(sessionID, opts.Key),
+			Files:           m.GetShareFiles(sessionID, opts.Key),
+			Hash:            m.GetContentHash(sessionID, opts.Key),
+			Visibility:      visibility,
+			Anonymize:       anonymizeThread,
+			Encrypt:         encryptThread,
+			Passphrase:      passphrase,
+			PassphraseCheck: check,
+			Force:           true,
+			Render: func(nav sessionNav, pageURL func(int) string) []string {
+				cfg := threadConfig(nav)
+				cfg.PageURL = pageURL
+				return converter.ConvertPages(messages, cfg, pageBytes(encryptThread))
+			},
+		}
+		if extras != nil {
+			current.Extras = func(nav sessionNav) []publish.File {
+				return extras.files(messages, threadConfig(nav))
 			}
 		}
-	}
+		// A gist's visibility cannot be changed in place, so the thread
+		// moves to a new gist and the old one is deleted once the new one
+		// is recorded.
+		var replacedID string
+		var replacedFiles []string
+		if current.ShareID != "" && opts.Backend == publish.BackendGist && sessionVisibility(m, sessionID, opts.Key, visibility) != visibility {
+			fmt.Fprintf(os.Stderr, "warning: gist visibility cannot be changed in place, creating a new %s gist\n", visibility)
+			replacedID, replacedFiles = current.ShareID, current.Files
+			current.ShareID = ""
+		}
 
-	var prevSessionURL, nextSessionURL string
-	if prevSessionID != "" || nextSessionID != "" {
-		m, _ := metadata.LoadMetadata(projectPath)
-		if prevSessionID != "" {
-			if prevGistID := m.GetGistID(prevSessionID); prevGistID != "" {
-				prevSessionURL = gist.PreviewURL(prevGistID)
-			}
+		previewURL, err := syncChain(projectPath, sessionID, current, true, opts)
+		if err != nil {
+			exitPublishError("failed to publish thread", err)
 		}
-		if nextSessionID != "" {
-			if nextGistID := m.GetGistID(nextSessionID); nextGistID != "" {
-				nextSessionURL = gist.PreviewURL(nextGistID)
+		updateIndex(projectPath, m.Family(sessionID)...)
+		fmt.Println(previewURL)
+
+		if replacedID != "" {
+			if err := opts.Publisher.Delete(replacedID, replacedFiles); err != nil && !publish.IsNotFound(err) {
+				oldURL := opts.Publisher.URL(replacedID, sessionFilename(sessionID))
+				exitPublishError("failed to delete the previous gist "+oldURL+", delete it by hand", err)
 			}
 		}
+		return
 	}
 
-	cfg := converter.Config{
-		Title:          title,
-		Username:       username,
-		UserInitials:   getInitials(username),
-		ProjectPath:    projectPath,
-		PrevSessionURL: prevSessionURL,
-		NextSessionURL: nextSessionURL,
+	m, _ = metadata.LoadMetadata(projectPath)
+	nav := buildNav(m, sessionID,
+		func(id string) string { return sessionURL(m, id, opts) },
+		func(id string) converter.SessionLink { return describeSession(projectPath, m, id, opts.Anonymizer) })
+	if anonymizeThread {
+		nav = nav.anonymize(opts.Anonymizer)
 	}
+	cfg := threadConfig(nav)
 	html := converter.Convert(messages, cfg)
+	if encryptThread {
+		html, err = encrypt.HTML(html, passphrase)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error encrypting thread: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	if err := os.WriteFile(outputPath, []byte(html), 0644); err != nil {
 		fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
@@ -217,27 +416,34 @@ func shareCmd(args []string) {
 
 	absOutput, _ := filepath.Abs(outputPath)
 	fmt.Printf("Thread exported to: %s\n", absOutput)
-}
 
-func extractTitle(messages []parser.Message) string {
-	for _, msg := range messages {
-		if msg.Role == "user" {
-			for _, block := range msg.Blocks {
-				if block.Type == "text" && block.Content != "" {
-					title := strings.TrimSpace(block.Content)
-					if strings.HasPrefix(title, "Caveat:") || strings.HasPrefix(title, "<") {
-						continue
-					}
-					if len(title) > 80 {
-						title = title[:77] + "..."
-					}
-					lines := strings.Split(title, "\n")
-					return lines[0]
-				}
+	if extras != nil {
+		base := strings.TrimSuffix(absOutput, filepath.Ext(absOutput))
+		for _, f := range extras.files(messages, cfg) {
+			path := base + filepath.Ext(f.Name)
+			if err := os.WriteFile(path, []byte(f.Content), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "error writing output: %v\n", err)
+				os.Exit(1)
 			}
+			fmt.Printf("Thread exported to: %s\n", path)
 		}
 	}
-	return "Claude Code Thread"
+}
+
+// sessionTitle returns the title set with 'claude-coding title', else the
+// summary Claude Code wrote for the session, else its first prompt. The
+// messages are parsed from sessionFile when needed and not given.
+func sessionTitle(m *metadata.Metadata, sessionID, sessionFile string, messages []parser.Message) string {
+	if title := m.GetTitle(sessionID); title != "" {
+		return title
+	}
+	if title := parser.ParseSummary(sessionFile); title != "" {
+		return title
+	}
+	if messages == nil {
+		messages, _ = parser.ParseFile(sessionFile)
+	}
+	return parser.ExtractTitle(messages)
 }
 
 func getSystemUsername() string {
@@ -264,131 +470,25 @@ func getInitials(name string) string {
 	return strings.ToUpper(string(parts[0][0]) + string(parts[len(parts)-1][0]))
 }
 
-func ensureSessionGist(projectPath string, m *metadata.Metadata, sessionID string) {
-	if sessionID == "" {
-		return
-	}
-	if m.GetGistID(sessionID) != "" {
-		return
-	}
-
-	sessionFile, err := parser.GetSessionFilePath(projectPath, sessionID)
-	if err != nil {
-		return
-	}
-
-	messages, err := parser.ParseFile(sessionFile)
-	if err != nil || len(messages) == 0 {
-		return
-	}
-
-	title := parser.ParseSummary(sessionFile)
-	if title == "" {
-		title = extractTitle(messages)
-	}
-
-	cfg := converter.Config{
-		Title:        title,
-		Username:     getSystemUsername(),
-		UserInitials: getInitials(getSystemUsername()),
-		ProjectPath:  projectPath,
-	}
-
-	html := converter.Convert(messages, cfg)
-	filename := "claude-code-" + sessionID + ".html"
-
-	gistID, _, err := gist.Create(filename, html)
-	if err != nil {
-		return
-	}
-	m.SetGistID(sessionID, gistID)
-	metadata.SaveMetadata(projectPath, m)
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
 
-func updateSessionChain(projectPath string, m *metadata.Metadata, currentSessionID, currentGistURL string) {
-	syncSessionGist(projectPath, m, m.GetPrevSessionID(currentSessionID), currentGistURL, "next")
-	syncSessionGist(projectPath, m, m.GetNextSessionID(currentSessionID), currentGistURL, "prev")
+func anonymizeSession(a *anonymize.Anonymizer, messages []parser.Message, cfg converter.Config) ([]parser.Message, converter.Config) {
+	cfg.Title = a.String(cfg.Title)
+	cfg.Note = a.String(cfg.Note)
+	cfg.Username = a.Author
+	cfg.UserInitials = getInitials(a.Author)
+	cfg.ProjectPath = a.String(cfg.ProjectPath)
+	return a.Messages(messages), cfg
 }
 
-func syncSessionGist(projectPath string, m *metadata.Metadata, sessionID, adjacentURL, direction string) {
-	if sessionID == "" {
-		return
-	}
-
-	sessionFile, err := parser.GetSessionFilePath(projectPath, sessionID)
-	if err != nil {
-		return
-	}
-
-	messages, err := parser.ParseFile(sessionFile)
-	if err != nil || len(messages) == 0 {
-		return
-	}
-
-	var prevURL, nextURL string
-	if direction == "next" {
-		nextURL = adjacentURL
-		if prevID := m.GetPrevSessionID(sessionID); prevID != "" {
-			if prevGistID := m.GetGistID(prevID); prevGistID != "" {
-				prevURL = gist.PreviewURL(prevGistID)
-			}
-		}
-	} else {
-		prevURL = adjacentURL
-		if nextID := m.GetNextSessionID(sessionID); nextID != "" {
-			if nextGistID := m.GetGistID(nextID); nextGistID != "" {
-				nextURL = gist.PreviewURL(nextGistID)
-			}
-		}
-	}
-
-	title := parser.ParseSummary(sessionFile)
-	if title == "" {
-		title = extractTitle(messages)
-	}
-
-	cfg := converter.Config{
-		Title:          title,
-		Username:       getSystemUsername(),
-		UserInitials:   getInitials(getSystemUsername()),
-		ProjectPath:    projectPath,
-		PrevSessionURL: prevURL,
-		NextSessionURL: nextURL,
-	}
-
-	html := converter.Convert(messages, cfg)
-	filename := "claude-code-" + sessionID + ".html"
-
-	existingGistID := m.GetGistID(sessionID)
-	var gistID string
+var errMissingPassphrase = errors.New("encrypted threads need --passphrase or CLAUDE_CODING_PASSPHRASE")
 
-	if existingGistID != "" {
-		_, err = gist.Update(existingGistID, filename, html)
-		if err != nil {
-			gistID, _, err = gist.Create(filename, html)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to create %s session gist: %v\n", direction, err)
-				return
-			}
-			m.SetGistID(sessionID, gistID)
-			metadata.SaveMetadata(projectPath, m)
-		} else {
-			gistID = existingGistID
-		}
-	} else {
-		gistID, _, err = gist.Create(filename, html)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to create %s session gist: %v\n", direction, err)
-			return
-		}
-		m.SetGistID(sessionID, gistID)
-		metadata.SaveMetadata(projectPath, m)
-	}
-
-	newURL := gist.PreviewURL(gistID)
-	if direction == "next" {
-		syncSessionGist(projectPath, m, m.GetPrevSessionID(sessionID), newURL, "next")
-	} else {
-		syncSessionGist(projectPath, m, m.GetNextSessionID(sessionID), newURL, "prev")
-	}
-}
+var errPassphraseMismatch = errors.New("it was encrypted with a different passphrase; share it on its own to re-encrypt it")
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
+	"github.com/priyanshujain/claude-coding/internal/converter"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+// sessionNav is the navigation between linked sessions shown on a
+// session's pages. Only published sessions are linked; unpublished ones are
+// skipped over.
+type sessionNav struct {
+	Kind      string
+	Prev      string
+	Next      string
+	Ancestors []converter.SessionLink
+	Branches  []converter.SessionLink
+	Index     []converter.SessionLink
+}
+
+// buildNav lays out the navigation of sessionID. url returns the link to a
+// session, or "" if it is not published, and describe its title and start
+// time.
+func buildNav(m *metadata.Metadata, sessionID string, url func(id string) string, describe func(id string) converter.SessionLink) sessionNav {
+	link := func(id, u, kind string) converter.SessionLink {
+		l := describe(id)
+		l.URL, l.Kind = u, kind
+		return l
+	}
+
+	nav := sessionNav{Kind: m.LinkKind(sessionID)}
+	for _, id := range m.Ancestors(sessionID) {
+		if u := url(id); u != "" {
+			nav.Ancestors = append(nav.Ancestors, link(id, u, m.LinkKind(id)))
+		}
+	}
+	if n := len(nav.Ancestors); n > 0 {
+		nav.Prev = nav.Ancestors[n-1].URL
+	}
+
+	seen := map[string]bool{sessionID: true}
+	for _, child := range m.GetChildren(sessionID) {
+		// An unpublished child is replaced by the first published session
+		// continuing from it.
+		for id := child.SessionID; id != "" && !seen[id]; id = m.GetNextSessionID(id) {
+			seen[id] = true
+			if u := url(id); u != "" {
+				nav.Branches = append(nav.Branches, link(id, u, child.Kind))
+				break
+			}
+		}
+	}
+	if n := len(nav.Branches); n > 0 {
+		nav.Next = nav.Branches[n-1].URL
+	}
+
+	for _, id := range m.Family(sessionID) {
+		if id == sessionID {
+			l := link(id, "", m.LinkKind(id))
+			l.Current = true
+			nav.Index = append(nav.Index, l)
+		} else if u := url(id); u != "" {
+			nav.Index = append(nav.Index, link(id, u, m.LinkKind(id)))
+		}
+	}
+	if len(nav.Index) < 2 {
+		nav.Index = nil
+	}
+	return nav
+}
+
+// apply sets the navigation fields of cfg.
+func (nav sessionNav) apply(cfg converter.Config) converter.Config {
+	cfg.PrevSessionURL = nav.Prev
+	cfg.NextSessionURL = nav.Next
+	cfg.LinkKind = nav.Kind
+	cfg.Ancestors = nav.Ancestors
+	cfg.Branches = nav.Branches
+	cfg.Index = nav.Index
+	return cfg
+}
+
+// anonymize hides what the titles of the linked sessions reveal on a page
+// shared anonymized, whether or not those sessions are anonymized
+// themselves.
+func (nav sessionNav) anonymize(a *anonymize.Anonymizer) sessionNav {
+	for _, links := range [][]converter.SessionLink{nav.Ancestors, nav.Branches, nav.Index} {
+		for i := range links {
+			links[i].Title = a.String(links[i].Title)
+		}
+	}
+	return nav
+}
+
+// describeSession returns the title a session is published under,
+// anonymized if the session is shared anonymized, and its start time.
+func describeSession(projectPath string, m *metadata.Metadata, sessionID string, a *anonymize.Anonymizer) converter.SessionLink {
+	sessionFile, err := parser.GetSessionFilePath(projectPath, sessionID)
+	if err != nil {
+		return converter.SessionLink{Title: sessionID}
+	}
+	messages, err := parser.ParseFile(sessionFile)
+	if err != nil {
+		return converter.SessionLink{Title: sessionID}
+	}
+	title := sessionTitle(m, sessionID, sessionFile, messages)
+	if m.IsAnonymized(sessionID) {
+		title = a.String(title)
+	}
+	return converter.SessionLink{Title: title, Date: startedAt(messages)}
+}
+
+// startedAt returns the time of the first timestamped message.
+func startedAt(messages []parser.Message) time.Time {
+	for _, msg := range messages {
+		if !msg.Timestamp.IsZero() {
+			return msg.Timestamp
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/preview"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+type syncOptions struct {
+	Backend    string
+	Key        string
+	Publisher  publish.Publisher
+	Passphrase string
+	Visibility gist.Visibility
+	Jobs       int
+	// Anonymizer is shared by every anonymized session of the chain, so
+	// they all use the same pseudonyms.
+	Anonymizer *anonymize.Anonymizer
+}
+
+func resolveBackend(publisherName, host string) (backend, resolvedHost string, cfg *config.Config, err error) {
+	cfg, err = config.Load()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if publisherName == "" {
+		publisherName = cfg.Publisher
+	}
+	if publisherName == "" {
+		publisherName = publish.BackendGist
+	}
+	backend, err = publish.ParseBackend(publisherName)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if host == "" {
+		host = cfg.GitHub.Host
+	}
+	if host == "" {
+		host = os.Getenv("GH_HOST")
+	}
+	return backend, host, cfg, nil
+}
+
+// newPublisher builds the publisher for backend, retrying transient
+// failures. A gist publisher is returned even without a token so that its
+// URLs can still be formed.
+func newPublisher(backend, host string, cfg *config.Config) (publish.Publisher, error) {
+	p, err := newBackend(backend, host, cfg)
+	if p == nil {
+		return nil, err
+	}
+	return publish.WithRetry(p), err
+}
+
+func newBackend(backend, host string, cfg *config.Config) (publish.Publisher, error) {
+	switch backend {
+	case publish.BackendDir:
+		return &publish.Dir{Path: cfg.Dir.Path, BaseURL: cfg.Dir.BaseURL}, nil
+	case publish.BackendS3:
+		region := cfg.S3.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		return &publish.S3{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          region,
+			Bucket:          cfg.S3.Bucket,
+			Prefix:          cfg.S3.Prefix,
+			PublicURL:       cfg.S3.PublicURL,
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	case publish.BackendGitPages:
+		return &publish.GitPages{
+			Repo:    cfg.GitPages.Repo,
+			Branch:  cfg.GitPages.Branch,
+			Dir:     cfg.GitPages.Dir,
+			BaseURL: cfg.GitPages.BaseURL,
+			Remote:  cfg.GitPages.Remote,
+		}, nil
+	case publish.BackendHTTP:
+		return &publish.HTTP{
+			Endpoint:  cfg.HTTP.Endpoint,
+			PublicURL: cfg.HTTP.PublicURL,
+			Token:     os.Getenv("CLAUDE_CODING_HTTP_TOKEN"),
+		}, nil
+	}
+
+	host = gist.NormalizeHost(host)
+	template, err := gistPreviewTemplate(host, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := gist.NewClient(gist.HostOptions{
+		Host:            host,
+		APIURL:          cfg.Host(host).APIURL,
+		PreviewTemplate: template,
+	})
+	return &publish.Gist{Client: client, Host: host, PreviewTemplate: template}, err
+}
+
+func gistPreviewTemplate(host string, cfg *config.Config) (string, error) {
+	template := cfg.Host(host).PreviewURL
+	if template == "" && (cfg.Preview.Provider != "" || cfg.Preview.URL != "") {
+		var err error
+		template, err = preview.Template(cfg.Preview.Provider, cfg.Preview.URL)
+		if err != nil {
+			return "", err
+		}
+	}
+	return gist.PreviewTemplate(host, template), nil
+}
+
+func shareKey(backend, host string) string {
+	if backend == publish.BackendGist && gist.IsEnterprise(host) {
+		return backend + ":" + gist.NormalizeHost(host)
+	}
+	return backend
+}
+
+func sessionFilename(sessionID string) string {
+	return "claude-code-" + sessionID + ".html"
+}
+
+// maxPageBytes keeps each uploaded file under the 1 MB beyond which the
+// Gists API truncates file content, which previews rely on.
+const maxPageBytes = 900 * 1024
+
+// pageBytes is the page size before encryption, which grows the page by
+// a third through base64 encoding.
+func pageBytes(encrypted bool) int {
+	if encrypted {
+		return maxPageBytes * 3 / 4
+	}
+	return maxPageBytes
+}
+
+func pageFilename(sessionID string, page int) string {
+	if page <= 1 {
+		return sessionFilename(sessionID)
+	}
+	return fmt.Sprintf("claude-code-%s.p%d.html", sessionID, page)
+}
+
+func sessionURL(m *metadata.Metadata, sessionID string, opts syncOptions) string {
+	if sessionID == "" || opts.Publisher == nil {
+		return ""
+	}
+	id := m.GetShareID(sessionID, opts.Key)
+	if id == "" {
+		return ""
+	}
+	return opts.Publisher.URL(id, sessionFilename(sessionID))
+}
+
+// sessionVisibility returns the visibility of a session's share under key.
+// Gists shared before visibility was recorded are public.
+func sessionVisibility(m *metadata.Metadata, sessionID, key string, fallback gist.Visibility) gist.Visibility {
+	if v, err := gist.ParseVisibility(m.GetVisibility(sessionID, key)); err == nil {
+		return v
+	}
+	if m.GetShareID(sessionID, key) != "" && strings.HasPrefix(key, publish.BackendGist) {
+		return gist.Public
+	}
+	return fallback
+}
+
+func publisherWithVisibility(p publish.Publisher, visibility gist.Visibility) publish.Publisher {
+	if r, ok := p.(*publish.Retry); ok {
+		scoped := *r
+		scoped.Publisher = publisherWithVisibility(r.Publisher, visibility)
+		return &scoped
+	}
+	if g, ok := p.(*publish.Gist); ok {
+		scoped := *g
+		scoped.Visibility = visibility
+		return &scoped
+	}
+	return p
+}
+
+// checkAuth verifies gist credentials before anything is uploaded. Other
+// backends report credential problems on the first upload.
+func checkAuth(p publish.Publisher) error {
+	g, ok := publish.Unwrap(p).(*publish.Gist)
+	if !ok {
+		return nil
+	}
+	if g.Client == nil {
+		return gist.ErrNoToken
+	}
+	if r, ok := p.(*publish.Retry); ok {
+		return r.Do(g.Client.CheckAuth)
+	}
+	return g.Client.CheckAuth()
+}
+
+func publishErrorMessage(err error) string {
+	switch publish.Classify(err) {
+	case publish.KindAuth:
+		return fmt.Sprintf("authentication failed: %v\ncheck the publisher credentials (for gists: GH_TOKEN, GITHUB_TOKEN or 'gh auth login')", err)
+	case publish.KindRateLimit:
+		if wait := publish.RetryAfter(err); wait > 0 {
+			return fmt.Sprintf("rate limited: %v\ntry again after %s", err, time.Now().Add(wait).Format("15:04:05"))
+		}
+		return fmt.Sprintf("rate limited: %v\ntry again later", err)
+	case publish.KindNotFound:
+		return fmt.Sprintf("not found: %v\nthe published thread may have been deleted", err)
+	case publish.KindNetwork:
+		return fmt.Sprintf("network error: %v\ncheck your connection and try again", err)
+	case publish.KindTooLarge:
+		return fmt.Sprintf("thread is too large to publish: %v", err)
+	}
+	return err.Error()
+}
+
+// exitPublishError reports a publishing failure and exits with the status
+// for its kind of error.
+func exitPublishError(action string, err error) {
+	fmt.Fprintf(os.Stderr, "error: %s: %s\n", action, publishErrorMessage(err))
+	os.Exit(publish.Classify(err).ExitCode())
+}
+
+// contentHash identifies a rendered thread before encryption, so unchanged
+// sessions can be skipped even though every encryption is randomized.
+func contentHash(files []publish.File) string {
+	h := sha256.New()
+	for i, f := range files {
+		if i > 0 {
+			h.Write([]byte{0})
+		}
+		h.Write([]byte(f.Content))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func recordShare(m *metadata.Metadata, item *syncItem, opts syncOptions) {
+	sessionID, visibility := item.SessionID, item.Visibility
+	m.SetShareID(sessionID, opts.Key, item.ShareID)
+	m.SetContentHash(sessionID, opts.Key, item.Hash)
+	m.SetShareFiles(sessionID, opts.Key, item.Files)
+	if m.IsUnshared(sessionID, opts.Key) {
+		m.SetUnshared(sessionID, opts.Key, false)
+	}
+	if opts.Backend == publish.BackendGist {
+		m.SetVisibility(sessionID, opts.Key, string(visibility))
+	}
+	check := item.PassphraseCheck
+	if !item.Encrypt {
+		check = ""
+	}
+	if m.GetPassphraseCheck(sessionID) != check {
+		m.SetPassphraseCheck(sessionID, check)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/config"
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+type shareRow struct {
+	Project       string    `json:"project"`
+	SessionID     string    `json:"session_id"`
+	Publisher     string    `json:"publisher"`
+	ID            string    `json:"id"`
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	ChainPosition int       `json:"chain_position"`
+	ChainLength   int       `json:"chain_length"`
+	Tags          []string  `json:"tags,omitempty"`
+}
+
+func sharesCmd(args []string) {
+	fs := flag.NewFlagSet("shares", flag.ExitOnError)
+
+	var projectPath string
+	var all bool
+	var format string
+	var tag string
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.BoolVar(&all, "all", false, "list shares for every project under ~/.claude/projects")
+	fs.StringVar(&format, "format", "table", "output format: table, json or csv")
+	fs.StringVar(&tag, "tag", "", "only list sessions with this tag")
+	fs.Parse(args)
+
+	if format != "table" && format != "json" && format != "csv" {
+		fmt.Fprintf(os.Stderr, "error: invalid format %q (want table, json or csv)\n", format)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var rows []shareRow
+	if all {
+		projectsDir, err := metadata.ProjectsDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		entries, err := os.ReadDir(projectsDir)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			rows = append(rows, projectShares(filepath.Join(projectsDir, entry.Name()), "", cfg)...)
+		}
+	} else {
+		if projectPath == "" {
+			cwd, err := os.Getwd()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			projectPath = cwd
+		}
+		projectPath, err = filepath.Abs(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		projectDir, err := metadata.ProjectDir(projectPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		rows = projectShares(projectDir, projectPath, cfg)
+	}
+
+	if tag != "" {
+		var tagged []shareRow
+		for _, r := range rows {
+			if slices.Contains(r.Tags, tag) {
+				tagged = append(tagged, r)
+			}
+		}
+		rows = tagged
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].UpdatedAt.After(rows[j].UpdatedAt)
+	})
+
+	switch format {
+	case "json":
+		if rows == nil {
+			rows = []shareRow{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rows)
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"project", "session_id", "publisher", "id", "url", "title", "updated_at", "chain_position", "chain_length", "tags"})
+		for _, r := range rows {
+			w.Write([]string{r.Project, r.SessionID, r.Publisher, r.ID, r.URL, r.Title, r.UpdatedAt.Format(time.RFC3339), strconv.Itoa(r.ChainPosition), strconv.Itoa(r.ChainLength), strings.Join(r.Tags, ";")})
+		}
+		w.Flush()
+	default:
+		if len(rows) == 0 {
+			fmt.Println("No shared threads found")
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if all {
+			fmt.Fprintln(w, "PROJECT\tUPDATED\tCHAIN\tTITLE\tURL")
+		} else {
+			fmt.Fprintln(w, "UPDATED\tCHAIN\tTITLE\tURL")
+		}
+		for _, r := range rows {
+			title := r.Title
+			if len(title) > 50 {
+				title = title[:47] + "..."
+			}
+			chain := fmt.Sprintf("%d/%d", r.ChainPosition, r.ChainLength)
+			updated := r.UpdatedAt.Local().Format("2006-01-02 15:04")
+			if all {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Project, updated, chain, title, r.URL)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", updated, chain, title, r.URL)
+			}
+		}
+		w.Flush()
+	}
+}
+
+// projectShares lists the shares recorded in the metadata file of a Claude
+// project directory. The project path is read from the session logs when
+// it is not known, since the folder name cannot be decoded reliably.
+func projectShares(projectDir, projectPath string, cfg *config.Config) []shareRow {
+	m, err := metadata.LoadMetadataDir(projectDir)
+	if err != nil && !errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v\n", projectDir, err)
+		return nil
+	}
+
+	var rows []shareRow
+	for sessionID, s := range m.Sessions {
+		shares := m.GetShares(sessionID)
+		if len(shares) == 0 {
+			continue
+		}
+
+		sessionFile := filepath.Join(projectDir, sessionID+".jsonl")
+		if projectPath == "" {
+			projectPath = parser.ParseCwd(sessionFile)
+		}
+		title := sessionTitle(m, sessionID, sessionFile, nil)
+		position, length := m.ChainPosition(sessionID)
+
+		for key, id := range shares {
+			rows = append(rows, shareRow{
+				SessionID:     sessionID,
+				Publisher:     key,
+				ID:            id,
+				URL:           shareURL(key, id, sessionID, cfg),
+				Title:         title,
+				UpdatedAt:     s.UpdatedAt,
+				ChainPosition: position,
+				ChainLength:   length,
+				Tags:          m.GetTags(sessionID),
+			})
+		}
+	}
+
+	if projectPath == "" {
+		projectPath = filepath.Base(projectDir)
+	}
+	for i := range rows {
+		rows[i].Project = projectPath
+	}
+	return rows
+}
+
+func shareURL(key, id, sessionID string, cfg *config.Config) string {
+	return publishedURL(key, id, sessionFilename(sessionID), cfg)
+}
+
+// publishedURL is the URL of a file published under the given publisher key
+// and ID.
+func publishedURL(key, id, filename string, cfg *config.Config) string {
+	backend, host, _ := strings.Cut(key, ":")
+	var p publish.Publisher
+	if backend == publish.BackendGist {
+		host = gist.NormalizeHost(host)
+		template, err := gistPreviewTemplate(host, cfg)
+		if err != nil {
+			return ""
+		}
+		p = &publish.Gist{Host: host, PreviewTemplate: template}
+	} else {
+		var err error
+		p, err = newPublisher(backend, "", cfg)
+		if err != nil {
+			return ""
+		}
+	}
+	return p.URL(id, filename)
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/converter"
+	"github.com/priyanshujain/claude-coding/internal/encrypt"
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+const defaultJobs = 4
+
+// syncItem is one session of a chain being published. Render produces the
+// unencrypted pages for the given navigation; pageURL is nil until the
+// session has been published. Extras, if set, produces the bundle files
+// uploaded after the pages. Title and Start are shown in the navigation of
+// the other sessions.
+type syncItem struct {
+	SessionID  string
+	Title      string
+	Start      time.Time
+	ShareID    string
+	Files      []string
+	Hash       string
+	Visibility gist.Visibility
+	Anonymize  bool
+	Encrypt    bool
+	Passphrase string
+	// PassphraseCheck is the verifier of Passphrase.
+	PassphraseCheck string
+	// Frozen items are linked from the others but never uploaded.
+	Frozen bool
+	Force  bool
+	Render func(nav sessionNav, pageURL func(int) string) []string
+	Extras func(nav sessionNav) []publish.File
+
+	changed bool
+	err     error
+}
+
+type syncTask struct {
+	item    *syncItem
+	files   []publish.File
+	hash    string
+	shareID string
+	err     error
+}
+
+// syncChain publishes every session linked to sessionID. Missing
+// sessions are created first (when createMissing is set), then each session
+// whose rendered links or content changed is updated; both phases upload in
+// parallel and metadata is written once at the end. current, if non-nil,
+// replaces the stored rendering of its session and is always uploaded.
+func syncChain(projectPath, sessionID string, current *syncItem, createMissing bool, opts syncOptions) (string, error) {
+	m, _ := metadata.LoadMetadata(projectPath)
+
+	var items []*syncItem
+	for _, id := range m.Family(sessionID) {
+		if current != nil && id == current.SessionID {
+			items = append(items, current)
+			continue
+		}
+		item, err := loadSyncItem(projectPath, m, id, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping session %s: %v\n", id, err)
+			continue
+		}
+		if item == nil || (item.ShareID == "" && (!createMissing || item.Frozen)) {
+			continue
+		}
+		if item.Frozen {
+			fmt.Fprintf(os.Stderr, "warning: not updating session %s: %v\n", id, errPassphraseMismatch)
+		}
+		items = append(items, item)
+	}
+
+	var creates []*syncTask
+	for _, item := range items {
+		if item.ShareID != "" {
+			continue
+		}
+		files := item.render(m, items, opts)
+		creates = append(creates, &syncTask{item: item, files: files, hash: contentHash(files)})
+	}
+	runTasks(creates, opts, publishTask)
+	for _, t := range creates {
+		if t.err != nil {
+			t.item.err = t.err
+			continue
+		}
+		t.item.ShareID, t.item.Files, t.item.Hash, t.item.Force, t.item.changed = t.shareID, fileNames(t.files), t.hash, false, true
+	}
+
+	// An update that has to fall back to a new upload changes that session's
+	// URL, so its neighbours are re-rendered in another round.
+	for round := 0; round <= len(items); round++ {
+		var updates []*syncTask
+		for _, item := range items {
+			if item.ShareID == "" || item.Frozen {
+				continue
+			}
+			files := item.render(m, items, opts)
+			hash := contentHash(files)
+			if hash == item.Hash && !item.Force {
+				continue
+			}
+			item.Force = false
+			updates = append(updates, &syncTask{item: item, files: files, hash: hash, shareID: item.ShareID})
+		}
+		if len(updates) == 0 {
+			break
+		}
+
+		runTasks(updates, opts, updateTask)
+		moved := false
+		for _, t := range updates {
+			if t.err != nil {
+				t.item.err = t.err
+				continue
+			}
+			if t.shareID != t.item.ShareID {
+				moved = true
+			}
+			t.item.ShareID, t.item.Files, t.item.Hash, t.item.changed = t.shareID, fileNames(t.files), t.hash, true
+		}
+		if !moved {
+			break
+		}
+	}
+
+	err := metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+		for _, item := range items {
+			if item.changed {
+				recordShare(m, item, opts)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save metadata: %w", err)
+	}
+
+	for _, item := range items {
+		if item.err != nil && item != current {
+			fmt.Fprintf(os.Stderr, "warning: failed to publish session %s: %v\n", item.SessionID, item.err)
+		}
+	}
+	if current == nil {
+		return "", nil
+	}
+	if current.ShareID == "" || (current.err != nil && !current.changed) {
+		return "", current.err
+	}
+	return opts.Publisher.URL(current.ShareID, sessionFilename(current.SessionID)), nil
+}
+
+// loadSyncItem prepares a stored session for syncing. It returns nil for
+// sessions that have been unshared from opts.Key or have nothing to show.
+func loadSyncItem(projectPath string, m *metadata.Metadata, sessionID string, opts syncOptions) (*syncItem, error) {
+	if m.IsUnshared(sessionID, opts.Key) {
+		return nil, nil
+	}
+
+	sessionFile, err := parser.GetSessionFilePath(projectPath, sessionID)
+	if err != nil {
+		return nil, nil
+	}
+
+	messages, err := parser.ParseFile(sessionFile)
+	if err != nil || len(messages) == 0 {
+		return nil, nil
+	}
+
+	encrypted := m.IsEncrypted(sessionID)
+	if encrypted && opts.Passphrase == "" {
+		return nil, errMissingPassphrase
+	}
+	// Re-encrypting a neighbour with another passphrase would lock out
+	// the readers who were given its own, so it is linked but left as is.
+	frozen := encrypted && !encrypt.Verify(m.GetPassphraseCheck(sessionID), opts.Passphrase)
+
+	cfg := converter.Config{
+		Title:        sessionTitle(m, sessionID, sessionFile, messages),
+		Note:         m.GetNote(sessionID),
+		Username:     getSystemUsername(),
+		UserInitials: getInitials(getSystemUsername()),
+		ProjectPath:  projectPath,
+	}
+	anonymized := m.IsAnonymized(sessionID)
+	if anonymized {
+		messages, cfg = anonymizeSession(opts.Anonymizer, messages, cfg)
+	}
+
+	var extras func(nav sessionNav) []publish.File
+	if m.IsBundled(sessionID) && !encrypted {
+		b, err := loadBundle(sessionID, sessionFile, m.HasRawJSONL(sessionID), opts.Anonymizer)
+		if err != nil {
+			return nil, err
+		}
+		extras = func(nav sessionNav) []publish.File {
+			return b.files(messages, nav.apply(cfg))
+		}
+	}
+
+	return &syncItem{
+		SessionID:       sessionID,
+		Title:           cfg.Title,
+		Start:           startedAt(messages),
+		ShareID:         m.GetShareID(sessionID, opts.Key),
+		Files:           m.GetShareFiles(sessionID, opts.Key),
+		Hash:            m.GetContentHash(sessionID, opts.Key),
+		Visibility:      sessionVisibility(m, sessionID, opts.Key, opts.Visibility),
+		Encrypt:         encrypted,
+		Passphrase:      opts.Passphrase,
+		PassphraseCheck: m.GetPassphraseCheck(sessionID),
+		Frozen:          frozen,
+		Render: func(nav sessionNav, pageURL func(int) string) []string {
+			cfg := nav.apply(cfg)
+			cfg.PageURL = pageURL
+			return converter.ConvertPages(messages, cfg, pageBytes(encrypted))
+		},
+		Extras: extras,
+	}, nil
+}
+
+// nav returns the navigation of item, linking the published items.
+func (item *syncItem) nav(m *metadata.Metadata, items []*syncItem, opts syncOptions) sessionNav {
+	byID := make(map[string]*syncItem, len(items))
+	for _, it := range items {
+		byID[it.SessionID] = it
+	}
+	url := func(id string) string {
+		if it, ok := byID[id]; ok && it.ShareID != "" {
+			return publish.Link(opts.Publisher, it.ShareID, sessionFilename(id))
+		}
+		return ""
+	}
+	describe := func(id string) converter.SessionLink {
+		if it, ok := byID[id]; ok {
+			return converter.SessionLink{Title: it.Title, Date: it.Start}
+		}
+		return converter.SessionLink{Title: id}
+	}
+	return buildNav(m, item.SessionID, url, describe)
+}
+
+// render lays out item with links to the published sessions linked to it
+// and, once it has been published, between its own pages.
+func (item *syncItem) render(m *metadata.Metadata, items []*syncItem, opts syncOptions) []publish.File {
+	nav := item.nav(m, items, opts)
+	if item.Anonymize {
+		nav = nav.anonymize(opts.Anonymizer)
+	}
+
+	var pageURL func(int) string
+	if shareID := item.ShareID; shareID != "" {
+		pageURL = func(page int) string {
+			return publish.Link(opts.Publisher, shareID, pageFilename(item.SessionID, page))
+		}
+	}
+
+	pages := item.Render(nav, pageURL)
+	files := make([]publish.File, len(pages))
+	for n, page := range pages {
+		files[n] = publish.File{Name: pageFilename(item.SessionID, n+1), Content: page}
+	}
+	if item.Extras != nil {
+		files = append(files, item.Extras(nav)...)
+	}
+	return files
+}
+
+// upload returns the files as they are uploaded, encrypting each page.
+func (item *syncItem) upload(files []publish.File) ([]publish.File, error) {
+	if !item.Encrypt {
+		return files, nil
+	}
+	encrypted := make([]publish.File, len(files))
+	for i, f := range files {
+		html, err := encrypt.HTML(f.Content, item.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		encrypted[i] = publish.File{Name: f.Name, Content: html}
+	}
+	return encrypted, nil
+}
+
+func fileNames(files []publish.File) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// runTasks runs fn for every task with at most opts.Jobs running at once.
+func runTasks(tasks []*syncTask, opts syncOptions, fn func(*syncTask, syncOptions)) {
+	jobs := opts.Jobs
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	sem := make(chan struct{}, jobs)
+	var wg sync.WaitGroup
+	for _, t := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t *syncTask) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(t, opts)
+		}(t)
+	}
+	wg.Wait()
+}
+
+func publishTask(t *syncTask, opts syncOptions) {
+	files, err := t.item.upload(t.files)
+	if err != nil {
+		t.err = err
+		return
+	}
+	pub := publisherWithVisibility(opts.Publisher, t.item.Visibility)
+	t.shareID, t.err = pub.Publish(files)
+}
+
+func updateTask(t *syncTask, opts syncOptions) {
+	files, err := t.item.upload(t.files)
+	if err != nil {
+		t.err = err
+		return
+	}
+	pub := publisherWithVisibility(opts.Publisher, t.item.Visibility)
+	err = pub.Update(t.shareID, files, t.item.Files)
+	if err == nil {
+		return
+	}
+	if !publish.IsNotFound(err) {
+		t.err = err
+		return
+	}
+	// The upload was deleted on the server, so publish it again.
+	t.shareID, t.err = pub.Publish(files)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/anonymize"
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+	"github.com/priyanshujain/claude-coding/internal/publish"
+)
+
+func unshareCmd(args []string) {
+	fs := flag.NewFlagSet("unshare", flag.ExitOnError)
+
+	var projectPath string
+	var sessionID string
+	var publisherName string
+	var host string
+	var passphrase string
+	var jobs int
+
+	fs.StringVar(&projectPath, "project", "", "project path")
+	fs.StringVar(&sessionID, "session", "", "session ID to unshare (default: current session)")
+	fs.StringVar(&publisherName, "publisher", "", "publishing backend: gist, dir, s3, http or git-pages (default from config, else gist)")
+	fs.StringVar(&host, "host", "", "GitHub host for gists (default from config, $GH_HOST, else github.com)")
+	fs.StringVar(&passphrase, "passphrase", "", "passphrase for re-rendering encrypted neighbours (default $CLAUDE_CODING_PASSPHRASE)")
+	fs.IntVar(&jobs, "jobs", defaultJobs, "number of linked sessions to upload in parallel")
+	fs.Parse(args)
+
+	if passphrase == "" {
+		passphrase = os.Getenv("CLAUDE_CODING_PASSPHRASE")
+	}
+
+	if projectPath == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		projectPath = cwd
+	}
+
+	projectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if sessionID == "" {
+		sessionID, err = parser.ResolveCurrentSessionID(projectPath, "")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error finding session: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	backend, host, cfgFile, err := resolveBackend(publisherName, host)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	publisher, err := newPublisher(backend, host, cfgFile)
+	if err != nil {
+		exitPublishError("cannot unshare", err)
+	}
+	opts := syncOptions{Backend: backend, Key: shareKey(backend, host), Publisher: publisher, Passphrase: passphrase, Visibility: gist.Secret, Jobs: jobs, Anonymizer: anonymize.New()}
+
+	m, err := metadata.LoadMetadata(projectPath)
+	if errors.Is(err, metadata.ErrNewerVersion) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	shareID := m.GetShareID(sessionID, opts.Key)
+	if shareID == "" {
+		fmt.Fprintf(os.Stderr, "error: session %s is not shared via %s\n", sessionID, opts.Key)
+		os.Exit(1)
+	}
+
+	if err := publisher.Delete(shareID, m.GetShareFiles(sessionID, opts.Key)); err != nil {
+		if !publish.IsNotFound(err) {
+			exitPublishError("failed to delete thread", err)
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s was already deleted\n", shareID)
+	}
+
+	err = metadata.WithLock(projectPath, func(m *metadata.Metadata) error {
+		m.SetShareID(sessionID, opts.Key, "")
+		m.SetUnshared(sessionID, opts.Key, true)
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error updating metadata: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := syncChain(projectPath, sessionID, nil, false, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to relink neighbours: %v\n", err)
+	}
+
+	updateIndex(projectPath, m.Family(sessionID)...)
+	fmt.Printf("Unshared session %s\n", sessionID)
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type DirConfig struct {
+	Path    string `json:"path,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+type S3Config struct {
+	Endpoint  string `json:"endpoint,omitempty"`
+	Region    string `json:"region,omitempty"`
+	Bucket    string `json:"bucket,omitempty"`
+	Prefix    string `json:"prefix,omitempty"`
+	PublicURL string `json:"public_url,omitempty"`
+}
+
+type HTTPConfig struct {
+	Endpoint  string `json:"endpoint,omitempty"`
+	PublicURL string `json:"public_url,omitempty"`
+}
+
+type GitPagesConfig struct {
+	Repo    string `json:"repo,omitempty"`
+	Branch  string `json:"branch,omitempty"`
+	Dir     string `json:"dir,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+	Remote  string `json:"remote,omitempty"`
+}
+
+type HostConfig struct {
+	APIURL     string `json:"api_url,omitempty"`
+	PreviewURL string `json:"preview_url,omitempty"`
+}
+
+type GitHubConfig struct {
+	Host  string                `json:"host,omitempty"`
+	Hosts map[string]HostConfig `json:"hosts,omitempty"`
+}
+
+type PreviewConfig struct {
+	Provider string `json:"provider,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+type Config struct {
+	Publisher string         `json:"publisher,omitempty"`
+	Preview   PreviewConfig  `json:"preview"`
+	GitHub    GitHubConfig   `json:"github"`
+	Dir       DirConfig      `json:"dir"`
+	S3        S3Config       `json:"s3"`
+	HTTP      HTTPConfig     `json:"http"`
+	GitPages  GitPagesConfig `json:"git_pages"`
+}
+
+const hostKeyPrefix = "github.hosts."
+
+func Path() (string, error) {
+	if path := os.Getenv("CLAUDE_CODING_CONFIG"); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".claude", "claude-coding.json"), nil
+}
+
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return &Config{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+		return &Config{}, err
+	}
+
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return &Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func Save(c *Config) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (c *Config) fields() map[string]*string {
+	return map[string]*string{
+		"publisher":          &c.Publisher,
+		"preview.provider":   &c.Preview.Provider,
+		"preview.url":        &c.Preview.URL,
+		"github.host":        &c.GitHub.Host,
+		"dir.path":           &c.Dir.Path,
+		"dir.base_url":       &c.Dir.BaseURL,
+		"s3.endpoint":        &c.S3.Endpoint,
+		"s3.region":          &c.S3.Region,
+		"s3.bucket":          &c.S3.Bucket,
+		"s3.prefix":          &c.S3.Prefix,
+		"s3.public_url":      &c.S3.PublicURL,
+		"http.endpoint":      &c.HTTP.Endpoint,
+		"http.public_url":    &c.HTTP.PublicURL,
+		"git_pages.repo":     &c.GitPages.Repo,
+		"git_pages.branch":   &c.GitPages.Branch,
+		"git_pages.dir":      &c.GitPages.Dir,
+		"git_pages.base_url": &c.GitPages.BaseURL,
+		"git_pages.remote":   &c.GitPages.Remote,
+	}
+}
+
+func (c *Config) Keys() []string {
+	var keys []string
+	for key := range c.fields() {
+		keys = append(keys, key)
+	}
+	for host := range c.GitHub.Hosts {
+		keys = append(keys, hostKeyPrefix+host+".api_url", hostKeyPrefix+host+".preview_url")
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Config) Get(key string) (string, error) {
+	if host, name, ok := splitHostKey(key); ok {
+		hc := c.GitHub.Hosts[host]
+		if name == "api_url" {
+			return hc.APIURL, nil
+		}
+		return hc.PreviewURL, nil
+	}
+
+	field, ok := c.fields()[key]
+	if !ok {
+		return "", fmt.Errorf("unknown config key %q", key)
+	}
+	return *field, nil
+}
+
+func (c *Config) Set(key, value string) error {
+	if host, name, ok := splitHostKey(key); ok {
+		if c.GitHub.Hosts == nil {
+			c.GitHub.Hosts = make(map[string]HostConfig)
+		}
+		hc := c.GitHub.Hosts[host]
+		if name == "api_url" {
+			hc.APIURL = value
+		} else {
+			hc.PreviewURL = value
+		}
+		if hc == (HostConfig{}) {
+			delete(c.GitHub.Hosts, host)
+		} else {
+			c.GitHub.Hosts[host] = hc
+		}
+		return nil
+	}
+
+	field, ok := c.fields()[key]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	*field = value
+	return nil
+}
+
+func (c *Config) Host(host string) HostConfig {
+	return c.GitHub.Hosts[host]
+}
+
+func splitHostKey(key string) (host, name string, ok bool) {
+	rest, found := strings.CutPrefix(key, hostKeyPrefix)
+	if !found {
+		return "", "", false
+	}
+	for _, name := range []string{"api_url", "preview_url"} {
+		if host, found := strings.CutSuffix(rest, "."+name); found && host != "" {
+			return host, name, true
+		}
+	}
+	return "", "", false
+}
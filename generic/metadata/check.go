@@ -0,0 +1,381 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ProblemKind string
+
+const (
+	ProblemCycle         ProblemKind = "cycle"
+	ProblemAsymmetric    ProblemKind = "asymmetric"
+	ProblemMissing       ProblemKind = "missing"
+	ProblemMultipleHeads ProblemKind = "multiple-heads"
+)
+
+// Problem is an inconsistency in the session links. Sessions lists the
+// sessions involved, starting with the one the problem was found at.
+type Problem struct {
+	Kind     ProblemKind
+	Sessions []string
+	Message  string
+}
+
+// SessionFiles reports the modification time of a session's JSONL file,
+// and whether the file exists at all.
+type SessionFiles func(sessionID string) (modTime time.Time, ok bool)
+
+// Fix is a link list changed by Repair.
+type Fix struct {
+	SessionID string
+	Field     string
+	Old       string
+	New       string
+}
+
+func (f Fix) String() string {
+	from, to := f.Old, f.New
+	if from == "" {
+		from = "(none)"
+	}
+	if to == "" {
+		to = "(none)"
+	}
+	return fmt.Sprintf("%s: %s %s -> %s", f.SessionID, f.Field, from, to)
+}
+
+type edge struct {
+	parent string
+	child  string
+}
+
+// Check reports loops, links that only one of the two sessions records,
+// links to sessions whose JSONL file is gone and linked groups of sessions
+// with more than one first session.
+func (m *Metadata) Check(files SessionFiles) []Problem {
+	var problems []Problem
+	for _, group := range m.Groups() {
+		problems = append(problems, m.checkGroup(group, files)...)
+	}
+	return problems
+}
+
+func (m *Metadata) checkGroup(group []string, files SessionFiles) []Problem {
+	var problems []Problem
+
+	for _, id := range group {
+		for _, child := range m.GetChildren(id) {
+			if !hasLink(m.GetParents(child.SessionID), id) {
+				problems = append(problems, Problem{
+					Kind:     ProblemAsymmetric,
+					Sessions: []string{id, child.SessionID},
+					Message:  fmt.Sprintf("%s lists %s as a child, which does not list it as a parent", id, child.SessionID),
+				})
+			}
+		}
+		for _, parent := range m.GetParents(id) {
+			if !hasLink(m.GetChildren(parent.SessionID), id) {
+				problems = append(problems, Problem{
+					Kind:     ProblemAsymmetric,
+					Sessions: []string{id, parent.SessionID},
+					Message:  fmt.Sprintf("%s lists %s as a parent, which does not list it as a child", id, parent.SessionID),
+				})
+			}
+		}
+		for _, linked := range m.linked(id) {
+			if _, ok := files(linked); !ok {
+				problems = append(problems, Problem{
+					Kind:     ProblemMissing,
+					Sessions: []string{id, linked},
+					Message:  fmt.Sprintf("%s links to %s, whose session file no longer exists", id, linked),
+				})
+			}
+		}
+	}
+
+	if cycle := findCycle(group, m.edges(group)); cycle != nil {
+		problems = append(problems, Problem{
+			Kind:     ProblemCycle,
+			Sessions: cycle,
+			Message:  fmt.Sprintf("links loop through %d sessions: %s", len(cycle), strings.Join(cycle, " -> ")),
+		})
+	}
+
+	var heads []string
+	for _, id := range group {
+		if len(m.GetParents(id)) == 0 {
+			heads = append(heads, id)
+		}
+	}
+	if len(heads) > 1 {
+		problems = append(problems, Problem{
+			Kind:     ProblemMultipleHeads,
+			Sessions: heads,
+			Message:  fmt.Sprintf("linked sessions have %d first sessions: %v", len(heads), heads),
+		})
+	}
+	return problems
+}
+
+// edges returns the links of the group claimed by either end, with the
+// kind the child recorded where both ends recorded one.
+func (m *Metadata) edges(group []string) map[edge]string {
+	edges := make(map[edge]string)
+	for _, id := range group {
+		for _, child := range m.GetChildren(id) {
+			if _, ok := edges[edge{id, child.SessionID}]; !ok {
+				edges[edge{id, child.SessionID}] = child.Kind
+			}
+		}
+		for _, parent := range m.GetParents(id) {
+			edges[edge{parent.SessionID, id}] = parent.Kind
+		}
+	}
+	return edges
+}
+
+// findCycle returns the sessions of a loop in the edges, in link order,
+// or nil if there is none.
+func findCycle(group []string, edges map[edge]string) []string {
+	children := make(map[string][]string)
+	for e := range edges {
+		children[e.parent] = append(children[e.parent], e.child)
+	}
+	for _, c := range children {
+		sort.Strings(c)
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		state[id] = visiting
+		path = append(path, id)
+		for _, child := range children[id] {
+			switch state[child] {
+			case visiting:
+				for i, p := range path {
+					if p == child {
+						return append([]string(nil), path[i:]...)
+					}
+				}
+			case 0:
+				if cycle := visit(child); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+		return nil
+	}
+	for _, id := range group {
+		if state[id] == 0 {
+			if cycle := visit(id); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// Repair fixes every linked group that has a problem. Links recorded by
+// only one end are restored on the other, sessions whose file is gone are
+// cut out by linking their parents to their children, loops are cut where
+// they return to their oldest session, and a session with several parents
+// keeps the one whose file was modified last.
+func (m *Metadata) Repair(files SessionFiles) []Fix {
+	var fixes []Fix
+	for _, group := range m.Groups() {
+		if len(m.checkGroup(group, files)) == 0 {
+			continue
+		}
+		fixes = append(fixes, m.relink(group, files)...)
+	}
+	return fixes
+}
+
+func (m *Metadata) relink(group []string, files SessionFiles) []Fix {
+	modTime := make(map[string]time.Time)
+	var missing []string
+	for _, id := range group {
+		if t, ok := files(id); ok {
+			modTime[id] = t
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	earlier := func(a, b string) bool {
+		if !modTime[a].Equal(modTime[b]) {
+			return modTime[a].Before(modTime[b])
+		}
+		return a < b
+	}
+
+	edges := m.edges(group)
+	for e := range edges {
+		if e.parent == e.child {
+			delete(edges, e)
+		}
+	}
+
+	for _, gone := range missing {
+		var in, out []edge
+		for e := range edges {
+			if e.child == gone {
+				in = append(in, e)
+			}
+			if e.parent == gone {
+				out = append(out, e)
+			}
+		}
+		for _, i := range in {
+			for _, o := range out {
+				if i.parent != o.child {
+					edges[edge{i.parent, o.child}] = edges[o]
+				}
+			}
+		}
+		for _, e := range append(in, out...) {
+			delete(edges, e)
+		}
+	}
+
+	// A loop is cut where it returns to its oldest session.
+	for {
+		cycle := findCycle(group, edges)
+		if cycle == nil {
+			break
+		}
+		oldest := 0
+		for i := range cycle {
+			if earlier(cycle[i], cycle[oldest]) {
+				oldest = i
+			}
+		}
+		delete(edges, edge{cycle[(oldest+len(cycle)-1)%len(cycle)], cycle[oldest]})
+	}
+
+	parents := make(map[string][]string)
+	for e := range edges {
+		parents[e.child] = append(parents[e.child], e.parent)
+	}
+	for child, ps := range parents {
+		sort.Slice(ps, func(i, j int) bool { return earlier(ps[i], ps[j]) })
+		for _, p := range ps[:len(ps)-1] {
+			delete(edges, edge{p, child})
+		}
+	}
+
+	wantParents := make(map[string][]Link)
+	wantChildren := make(map[string][]Link)
+	for e, kind := range edges {
+		wantParents[e.child] = append(wantParents[e.child], Link{SessionID: e.parent, Kind: kind})
+		wantChildren[e.parent] = append(wantChildren[e.parent], Link{SessionID: e.child, Kind: kind})
+	}
+
+	var fixes []Fix
+	for _, id := range group {
+		s, ok := m.Sessions[id]
+		newParents, newChildren := wantParents[id], wantChildren[id]
+		if !ok && len(newParents) == 0 && len(newChildren) == 0 {
+			continue
+		}
+		// The most recent child is the one navigation continues with.
+		sort.Slice(newChildren, func(i, j int) bool {
+			return earlier(newChildren[i].SessionID, newChildren[j].SessionID)
+		})
+
+		changed := false
+		if old, want := formatLinks(s.Parents), formatLinks(newParents); old != want {
+			fixes = append(fixes, Fix{SessionID: id, Field: "parents", Old: old, New: want})
+			s.Parents = newParents
+			changed = true
+		}
+		if old, want := formatLinks(s.Children), formatLinks(newChildren); old != want {
+			fixes = append(fixes, Fix{SessionID: id, Field: "children", Old: old, New: want})
+			s.Children = newChildren
+			changed = true
+		}
+		if changed {
+			s.UpdatedAt = time.Now()
+			m.Sessions[id] = s
+		}
+	}
+	return fixes
+}
+
+// Groups splits the sessions into groups connected by links in either
+// direction, including linked sessions that have no entry of their own.
+// Groups and their members are sorted by session ID.
+func (m *Metadata) Groups() [][]string {
+	neighbours := make(map[string][]string)
+	for id := range m.Sessions {
+		if _, ok := neighbours[id]; !ok {
+			neighbours[id] = nil
+		}
+		for _, linked := range m.linked(id) {
+			if linked == "" {
+				continue
+			}
+			neighbours[id] = append(neighbours[id], linked)
+			neighbours[linked] = append(neighbours[linked], id)
+		}
+	}
+
+	ids := make([]string, 0, len(neighbours))
+	for id := range neighbours {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var groups [][]string
+	seen := make(map[string]bool)
+	for _, start := range ids {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		group := []string{start}
+		for i := 0; i < len(group); i++ {
+			for _, n := range neighbours[group[i]] {
+				if !seen[n] {
+					seen[n] = true
+					group = append(group, n)
+				}
+			}
+		}
+		sort.Strings(group)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func hasLink(links []Link, sessionID string) bool {
+	for _, l := range links {
+		if l.SessionID == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// formatLinks lists the linked sessions, noting kinds other than clear.
+func formatLinks(links []Link) string {
+	ids := make([]string, len(links))
+	for i, l := range links {
+		ids[i] = l.SessionID
+		if l.Kind != "" && l.Kind != LinkClear {
+			ids[i] += " (" + l.Kind + ")"
+		}
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,120 @@
+package metadata
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func clearLinks(ids ...string) []Link {
+	links := make([]Link, len(ids))
+	for i, id := range ids {
+		links[i] = Link{SessionID: id, Kind: LinkClear}
+	}
+	return links
+}
+
+// sessionFiles reports every session file as present, modified in the
+// order given, except for the missing ones.
+func sessionFiles(order []string, missing ...string) SessionFiles {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return func(sessionID string) (time.Time, bool) {
+		if slices.Contains(missing, sessionID) {
+			return time.Time{}, false
+		}
+		return base.Add(time.Duration(slices.Index(order, sessionID)) * time.Hour), true
+	}
+}
+
+func TestCheckAndRepair(t *testing.T) {
+	tests := []struct {
+		name     string
+		sessions map[string]Session
+		missing  []string
+		want     []ProblemKind
+		// repaired lists the parents and children of each session after
+		// Repair, as formatLinks prints them.
+		repaired map[string][2]string
+	}{
+		{
+			name: "consistent",
+			sessions: map[string]Session{
+				"a": {Children: clearLinks("b")},
+				"b": {Parents: clearLinks("a")},
+			},
+			repaired: map[string][2]string{"a": {"", "b"}, "b": {"a", ""}},
+		},
+		{
+			name: "cycle",
+			sessions: map[string]Session{
+				"a": {Parents: clearLinks("c"), Children: clearLinks("b")},
+				"b": {Parents: clearLinks("a"), Children: clearLinks("c")},
+				"c": {Parents: clearLinks("b"), Children: clearLinks("a")},
+			},
+			want:     []ProblemKind{ProblemCycle},
+			repaired: map[string][2]string{"a": {"", "b"}, "b": {"a", "c"}, "c": {"b", ""}},
+		},
+		{
+			name: "asymmetric link",
+			sessions: map[string]Session{
+				"a": {Children: clearLinks("b")},
+				"b": {},
+			},
+			want:     []ProblemKind{ProblemAsymmetric, ProblemMultipleHeads},
+			repaired: map[string][2]string{"a": {"", "b"}, "b": {"a", ""}},
+		},
+		{
+			name: "missing session file",
+			sessions: map[string]Session{
+				"a": {Children: clearLinks("b")},
+				"b": {Parents: clearLinks("a"), Children: clearLinks("c")},
+				"c": {Parents: clearLinks("b")},
+			},
+			missing:  []string{"b"},
+			want:     []ProblemKind{ProblemMissing, ProblemMissing},
+			repaired: map[string][2]string{"a": {"", "c"}, "b": {"", ""}, "c": {"a", ""}},
+		},
+		{
+			name: "multiple heads",
+			sessions: map[string]Session{
+				"a": {Children: clearLinks("c")},
+				"b": {Children: []Link{{SessionID: "c", Kind: LinkResume}}},
+				"c": {Parents: append(clearLinks("a"), Link{SessionID: "b", Kind: LinkResume})},
+			},
+			want:     []ProblemKind{ProblemMultipleHeads},
+			repaired: map[string][2]string{"a": {"", ""}, "b": {"", "c (resume)"}, "c": {"b (resume)", ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Metadata{Version: SchemaVersion, Sessions: tt.sessions}
+			files := sessionFiles([]string{"a", "b", "c"}, tt.missing...)
+
+			var got []ProblemKind
+			for _, p := range m.Check(files) {
+				got = append(got, p.Kind)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Fatalf("Check() = %v, want %v", got, want)
+			}
+
+			fixes := m.Repair(files)
+			if len(tt.want) == 0 && len(fixes) > 0 {
+				t.Errorf("Repair() changed consistent links: %v", fixes)
+			}
+			for id, links := range tt.repaired {
+				s := m.Sessions[id]
+				if got := [2]string{formatLinks(s.Parents), formatLinks(s.Children)}; got != links {
+					t.Errorf("after Repair, %s has parents/children %q, want %q", id, got, links)
+				}
+			}
+			if problems := m.Check(files); len(problems) > 0 {
+				t.Errorf("Check() after Repair = %v, want none", problems)
+			}
+		})
+	}
+}
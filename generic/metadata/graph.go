@@ -0,0 +1,269 @@
+package metadata
+
+import (
+	"sort"
+	"time"
+)
+
+// Sessions form a graph: a session continues from its parents and is
+// continued by its children. A session normally has a single parent;
+// several children mean the history branched, for example after clearing
+// twice from the same session or resuming an older one.
+
+// How a session was started from its parent.
+const (
+	LinkClear  = "clear"
+	LinkResume = "resume"
+	LinkFork   = "fork"
+)
+
+type Link struct {
+	SessionID string `json:"session_id"`
+	Kind      string `json:"kind,omitempty"`
+}
+
+func (m *Metadata) GetParents(sessionID string) []Link {
+	return m.Sessions[sessionID].Parents
+}
+
+func (m *Metadata) GetChildren(sessionID string) []Link {
+	return m.Sessions[sessionID].Children
+}
+
+// GetPrevSessionID returns the session this one continues from, or "" for
+// the first session of a history.
+func (m *Metadata) GetPrevSessionID(sessionID string) string {
+	if parents := m.GetParents(sessionID); len(parents) > 0 {
+		return parents[0].SessionID
+	}
+	return ""
+}
+
+// GetNextSessionID returns the most recent session continuing from this
+// one, or "" if there is none.
+func (m *Metadata) GetNextSessionID(sessionID string) string {
+	if children := m.GetChildren(sessionID); len(children) > 0 {
+		return children[len(children)-1].SessionID
+	}
+	return ""
+}
+
+// LinkKind returns how sessionID was started from its parent.
+func (m *Metadata) LinkKind(sessionID string) string {
+	if parents := m.GetParents(sessionID); len(parents) > 0 {
+		return parents[0].Kind
+	}
+	return ""
+}
+
+func (m *Metadata) ResolveLatestSession(startSessionID string) (latestSession, prevSession string) {
+	if startSessionID == "" {
+		return "", ""
+	}
+
+	if _, ok := m.Sessions[startSessionID]; !ok {
+		return startSessionID, ""
+	}
+
+	prev := ""
+	current := startSessionID
+	seen := map[string]bool{current: true}
+	for {
+		next := m.GetNextSessionID(current)
+		if next == "" || seen[next] {
+			return current, prev
+		}
+		seen[next] = true
+		prev = current
+		current = next
+	}
+}
+
+// Ancestors returns the sessions sessionID descends from through their
+// first parents, oldest first. Loops in the links are not followed twice.
+func (m *Metadata) Ancestors(sessionID string) []string {
+	var ancestors []string
+	seen := map[string]bool{sessionID: true}
+	for prev := m.GetPrevSessionID(sessionID); prev != "" && !seen[prev]; prev = m.GetPrevSessionID(prev) {
+		seen[prev] = true
+		ancestors = append(ancestors, prev)
+	}
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+	return ancestors
+}
+
+// Chain returns the line of sessions through sessionID, oldest first: its
+// ancestors, the session itself and its most recent descendants. Loops in
+// the links are not followed twice.
+func (m *Metadata) Chain(sessionID string) []string {
+	chain := append(m.Ancestors(sessionID), sessionID)
+	seen := make(map[string]bool, len(chain))
+	for _, id := range chain {
+		seen[id] = true
+	}
+	for next := m.GetNextSessionID(sessionID); next != "" && !seen[next]; next = m.GetNextSessionID(next) {
+		seen[next] = true
+		chain = append(chain, next)
+	}
+	return chain
+}
+
+// ChainPosition returns the 1-based position of sessionID in its chain and
+// the chain length.
+func (m *Metadata) ChainPosition(sessionID string) (position, length int) {
+	chain := m.Chain(sessionID)
+	for i, id := range chain {
+		if id == sessionID {
+			return i + 1, len(chain)
+		}
+	}
+	return 1, len(chain)
+}
+
+// Family returns every session connected to sessionID by links in either
+// direction, parents before their children and otherwise in the order the
+// links were made.
+func (m *Metadata) Family(sessionID string) []string {
+	members := map[string]bool{sessionID: true}
+	queue := []string{sessionID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, linked := range m.linked(id) {
+			if !members[linked] {
+				members[linked] = true
+				queue = append(queue, linked)
+			}
+		}
+	}
+
+	var roots []string
+	for id := range members {
+		if m.GetPrevSessionID(id) == "" || !members[m.GetPrevSessionID(id)] {
+			roots = append(roots, id)
+		}
+	}
+	sort.Strings(roots)
+
+	var family []string
+	seen := make(map[string]bool, len(members))
+	var visit func(id string)
+	visit = func(id string) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		family = append(family, id)
+		for _, child := range m.GetChildren(id) {
+			if members[child.SessionID] {
+				visit(child.SessionID)
+			}
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+	// Sessions only reachable through a loop.
+	var rest []string
+	for id := range members {
+		if !seen[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Strings(rest)
+	for _, id := range rest {
+		visit(id)
+	}
+	return family
+}
+
+// LinkSession records that newID was started from parentID in the given
+// way. Existing links are kept, so a session can have several children.
+// With an empty parentID it only makes sure newID has an entry.
+func (m *Metadata) LinkSession(parentID, newID, kind string) {
+	now := time.Now()
+	if parentID != "" && parentID != newID {
+		parent := m.Sessions[parentID]
+		parent.Children = addLink(parent.Children, Link{SessionID: newID, Kind: kind})
+		parent.UpdatedAt = now
+		m.Sessions[parentID] = parent
+	}
+
+	newSession, ok := m.Sessions[newID]
+	if parentID != "" && parentID != newID {
+		newSession.Parents = addLink(newSession.Parents, Link{SessionID: parentID, Kind: kind})
+	} else if ok {
+		return
+	}
+	newSession.UpdatedAt = now
+	m.Sessions[newID] = newSession
+}
+
+// UnlinkSession removes the link between parentID and childID from both
+// sessions.
+func (m *Metadata) UnlinkSession(parentID, childID string) {
+	if s, ok := m.Sessions[parentID]; ok {
+		s.Children = removeLink(s.Children, childID)
+		m.Sessions[parentID] = s
+	}
+	if s, ok := m.Sessions[childID]; ok {
+		s.Parents = removeLink(s.Parents, parentID)
+		m.Sessions[childID] = s
+	}
+}
+
+// RemoveSession deletes a session's entry. Its children are linked to its
+// parents in its place, keeping the kind of the link to each child, so the
+// history stays connected.
+func (m *Metadata) RemoveSession(sessionID string) {
+	parents, children := m.GetParents(sessionID), m.GetChildren(sessionID)
+	for _, p := range parents {
+		m.UnlinkSession(p.SessionID, sessionID)
+	}
+	for _, c := range children {
+		m.UnlinkSession(sessionID, c.SessionID)
+	}
+	delete(m.Sessions, sessionID)
+	for _, p := range parents {
+		for _, c := range children {
+			_, hasParent := m.Sessions[p.SessionID]
+			_, hasChild := m.Sessions[c.SessionID]
+			if hasParent && hasChild && p.SessionID != c.SessionID {
+				m.LinkSession(p.SessionID, c.SessionID, c.Kind)
+			}
+		}
+	}
+}
+
+// linked returns the parents and children of sessionID.
+func (m *Metadata) linked(sessionID string) []string {
+	var ids []string
+	for _, l := range m.GetParents(sessionID) {
+		ids = append(ids, l.SessionID)
+	}
+	for _, l := range m.GetChildren(sessionID) {
+		ids = append(ids, l.SessionID)
+	}
+	return ids
+}
+
+func addLink(links []Link, l Link) []Link {
+	for _, existing := range links {
+		if existing.SessionID == l.SessionID {
+			return links
+		}
+	}
+	return append(links, l)
+}
+
+func removeLink(links []Link, sessionID string) []Link {
+	var kept []Link
+	for _, l := range links {
+		if l.SessionID != sessionID {
+			kept = append(kept, l)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,178 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// IndexFileName is the index of sessions across all projects, kept in
+	// ~/.claude next to the projects directory.
+	IndexFileName = "claude-coding-index.json"
+
+	// IndexVersion is the version of the index format this build reads and
+	// writes.
+	IndexVersion = 1
+)
+
+// IndexEntry summarizes a session so it can be listed and searched without
+// parsing its JSONL file. Size and ModTime are those of the file when the
+// counts were taken, so unchanged files are not parsed again.
+type IndexEntry struct {
+	SessionID   string            `json:"session_id"`
+	ProjectPath string            `json:"project_path,omitempty"`
+	ProjectDir  string            `json:"project_dir"`
+	Title       string            `json:"title,omitempty"`
+	Summary     string            `json:"summary,omitempty"`
+	Note        string            `json:"note,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Start       time.Time         `json:"start"`
+	End         time.Time         `json:"end"`
+	Messages    int               `json:"messages"`
+	Prompts     int               `json:"prompts"`
+	ToolCalls   int               `json:"tool_calls"`
+	Shares      map[string]string `json:"shares,omitempty"`
+	Missing     bool              `json:"missing,omitempty"`
+	Size        int64             `json:"size"`
+	ModTime     time.Time         `json:"mod_time"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// DisplayTitle is the stored title, else the one derived from the session.
+func (e IndexEntry) DisplayTitle() string {
+	if e.Title != "" {
+		return e.Title
+	}
+	return e.Summary
+}
+
+// Index is the global session index. Unlike project metadata it only
+// caches what can be read elsewhere, so it can always be rebuilt.
+type Index struct {
+	Version  int                   `json:"version"`
+	Sessions map[string]IndexEntry `json:"sessions"`
+}
+
+func newIndex() *Index {
+	return &Index{Version: IndexVersion, Sessions: make(map[string]IndexEntry)}
+}
+
+func IndexPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".claude", IndexFileName), nil
+}
+
+// LoadIndex reads the global index for reading only. A missing index reads
+// as empty; os.Stat on IndexPath tells whether it was ever built.
+func LoadIndex() (*Index, error) {
+	path, err := IndexPath()
+	if err != nil {
+		return newIndex(), err
+	}
+	idx, err := readIndex(path)
+	if idx == nil {
+		idx = newIndex()
+	}
+	return idx, err
+}
+
+func readIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrCorrupt, err)
+	}
+	if idx.Sessions == nil {
+		idx.Sessions = make(map[string]IndexEntry)
+	}
+	if idx.Version > IndexVersion {
+		return &idx, fmt.Errorf("%s: %w (index version %d, this build supports %d); upgrade claude-coding", path, ErrNewerVersion, idx.Version, IndexVersion)
+	}
+	return &idx, nil
+}
+
+// WithIndex changes the global index under an exclusive lock, writing it
+// back atomically unless fn fails. A corrupt index is started afresh,
+// since everything in it can be rebuilt.
+func WithIndex(fn func(*Index) error) error {
+	path, err := IndexPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	lockFile, err := lock(path)
+	if err != nil {
+		return err
+	}
+	defer unlock(lockFile)
+
+	idx, err := readIndex(path)
+	if errors.Is(err, ErrCorrupt) {
+		fmt.Fprintf(os.Stderr, "warning: %s was corrupt; starting afresh, run 'claude-coding index' to rebuild it\n", path)
+		idx, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		idx = newIndex()
+	}
+
+	if err := fn(idx); err != nil {
+		return err
+	}
+
+	idx.Version = IndexVersion
+	// Not indented: the index holds every session on the machine and is
+	// read by every hook.
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return err
+	}
+	return writeFile(path, data)
+}
+
+// Entries returns the indexed sessions, most recently active first.
+func (idx *Index) Entries() []IndexEntry {
+	entries := make([]IndexEntry, 0, len(idx.Sessions))
+	for _, e := range idx.Sessions {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].LastActive(), entries[j].LastActive()
+		if !a.Equal(b) {
+			return a.After(b)
+		}
+		return entries[i].SessionID < entries[j].SessionID
+	})
+	return entries
+}
+
+// LastActive is when the session was last seen active: its last message,
+// else its first, else when it was indexed.
+func (e IndexEntry) LastActive() time.Time {
+	if !e.End.IsZero() {
+		return e.End
+	}
+	if !e.Start.IsZero() {
+		return e.Start
+	}
+	return e.UpdatedAt
+}
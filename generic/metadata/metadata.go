@@ -2,21 +2,55 @@ package metadata
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"syscall"
 	"time"
 )
 
 type Session struct {
-	PrevSessionID string    `json:"prev_session_id,omitempty"`
-	NextSessionID string    `json:"next_session_id,omitempty"`
-	GistID        string    `json:"gist_id,omitempty"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	Parents         []Link              `json:"parents,omitempty"`
+	Children        []Link              `json:"children,omitempty"`
+	GistID          string              `json:"gist_id,omitempty"`
+	Shares          map[string]string   `json:"shares,omitempty"`
+	ContentHashes   map[string]string   `json:"content_hashes,omitempty"`
+	ShareFiles      map[string][]string `json:"share_files,omitempty"`
+	Combined        map[string]Combined `json:"combined,omitempty"`
+	Anonymize       bool                `json:"anonymize,omitempty"`
+	Encrypted       bool                `json:"encrypted,omitempty"`
+	PassphraseCheck string              `json:"passphrase_check,omitempty"`
+	Bundle          bool                `json:"bundle,omitempty"`
+	RawJSONL        bool                `json:"raw_jsonl,omitempty"`
+	Visibilities    map[string]string   `json:"visibilities,omitempty"`
+	Unshared        map[string]bool     `json:"unshared,omitempty"`
+	Title           string              `json:"title,omitempty"`
+	Note            string              `json:"note,omitempty"`
+	Tags            []string            `json:"tags,omitempty"`
+	UpdatedAt       time.Time           `json:"updated_at"`
 }
 
+// Combined is a published export of a whole chain in one document. It is
+// recorded on the first session of the chain, per publisher.
+type Combined struct {
+	ID         string   `json:"id"`
+	Files      []string `json:"files,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
+}
+
+const (
+	FileName = "claude-coding-metadata.json"
+
+	// LegacyFileName is where metadata was kept before schema version 1.
+	LegacyFileName = "workbench-metadata.json"
+)
+
 type Metadata struct {
+	Version  int                `json:"version"`
 	Sessions map[string]Session `json:"sessions"`
 }
 
@@ -26,81 +60,230 @@ func encodeProjectPath(projectPath string) string {
 	return folder
 }
 
-func metadataPath(projectPath string) (string, error) {
+func ProjectsDir() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	folder := encodeProjectPath(projectPath)
-	return filepath.Join(homeDir, ".claude", "projects", folder, "workbench-metadata.json"), nil
+	return filepath.Join(homeDir, ".claude", "projects"), nil
+}
+
+func ProjectDir(projectPath string) (string, error) {
+	projectsDir, err := ProjectsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectsDir, encodeProjectPath(projectPath)), nil
+}
+
+// sourcePath returns the file to read the metadata at path from: path
+// itself, or the legacy file next to it if only that exists.
+func sourcePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		legacy := filepath.Join(filepath.Dir(path), LegacyFileName)
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return path
+}
+
+// ErrCorrupt is returned when a metadata file exists but cannot be parsed.
+var ErrCorrupt = errors.New("metadata file is corrupt")
+
+func newMetadata() *Metadata {
+	return &Metadata{Version: SchemaVersion, Sessions: make(map[string]Session)}
 }
 
+// LoadMetadata reads the metadata of a project for reading only.
 func LoadMetadata(projectPath string) (*Metadata, error) {
-	path, err := metadataPath(projectPath)
+	projectDir, err := ProjectDir(projectPath)
 	if err != nil {
-		return &Metadata{Sessions: make(map[string]Session)}, err
+		return newMetadata(), err
 	}
+	return LoadMetadataDir(projectDir)
+}
+
+// LoadMetadataDir reads the metadata in a Claude project directory,
+// falling back to the legacy file name for projects not yet migrated.
+func LoadMetadataDir(projectDir string) (*Metadata, error) {
+	return LoadMetadataFile(sourcePath(filepath.Join(projectDir, FileName)))
+}
 
+// LoadMetadataFile reads a metadata file, migrating older versions in
+// memory. If the file is corrupt, the backup written alongside it is
+// returned instead; only WithLock repairs the file itself. Files from a
+// newer version are returned as far as they could be read, together with
+// ErrNewerVersion.
+func LoadMetadataFile(path string) (*Metadata, error) {
+	m, _, _, err := readFile(path)
+	if errors.Is(err, ErrCorrupt) {
+		if backup, _, _, backupErr := readFile(backupPath(path)); backupErr == nil && backup != nil {
+			return backup, nil
+		}
+	}
+	if m == nil {
+		m = newMetadata()
+	}
+	return m, err
+}
+
+// readFile parses and migrates the metadata file at path, returning the
+// schema version and raw content it was read with. The metadata is nil if
+// the file does not exist.
+func readFile(path string) (*Metadata, int, []byte, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &Metadata{Sessions: make(map[string]Session)}, nil
+			return nil, SchemaVersion, nil, nil
 		}
-		return &Metadata{Sessions: make(map[string]Session)}, err
+		return nil, 0, nil, err
 	}
+	m, version, err := decode(path, data)
+	return m, version, data, err
+}
 
-	var m Metadata
-	if err := json.Unmarshal(data, &m); err != nil {
-		return &Metadata{Sessions: make(map[string]Session)}, err
+func backupPath(path string) string {
+	return path + ".bak"
+}
+
+// writeFile replaces the file at path atomically: readers and a crash
+// mid-write see either the old content or the new, never a torn file.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
 	}
+	defer os.Remove(tmp.Name())
 
-	if m.Sessions == nil {
-		m.Sessions = make(map[string]Session)
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
 	}
-	return &m, nil
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-func SaveMetadata(projectPath string, m *Metadata) error {
-	path, err := metadataPath(projectPath)
-	if err != nil {
-		return err
+// recoverFile moves a corrupt metadata file aside and returns the content
+// of its backup, or empty metadata if there is no usable backup.
+func recoverFile(path string) (*Metadata, error) {
+	aside := fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(path, aside); err != nil {
+		return nil, err
 	}
 
-	data, err := json.MarshalIndent(m, "", "  ")
+	m, _, _, err := readFile(backupPath(path))
+	if err == nil && m != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s was corrupt; restored the last good copy and moved the damaged file to %s\n", path, aside)
+		return m, nil
+	}
+	fmt.Fprintf(os.Stderr, "warning: %s was corrupt and no backup could be read; starting afresh and moved the damaged file to %s\n", path, aside)
+	return newMetadata(), nil
+}
+
+func lock(path string) (*os.File, error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
 	}
+	return f, nil
+}
 
-	return os.WriteFile(path, data, 0644)
+func unlock(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
 }
 
+// WithLock is the only way to change metadata. It holds an exclusive lock
+// on the project's metadata while it loads the file, runs fn and, unless fn
+// fails, writes the result back atomically along with a backup copy. A
+// corrupt file is moved aside and recovered from that backup.
+//
+// Older files are migrated to SchemaVersion, keeping a copy of the file as
+// it was (<file>.v<version>.bak); the legacy workbench-metadata.json is
+// replaced by FileName. Files written by a newer version are refused.
 func WithLock(projectPath string, fn func(*Metadata) error) error {
-	path, err := metadataPath(projectPath)
+	projectDir, err := ProjectDir(projectPath)
 	if err != nil {
 		return err
 	}
+	return WithLockDir(projectDir, fn)
+}
 
+// WithLockDir is WithLock for the metadata in a Claude project directory.
+func WithLockDir(projectDir string, fn func(*Metadata) error) error {
+	path := filepath.Join(projectDir, FileName)
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
-	lockPath := path + ".lock"
-	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0644)
+	lockFile, err := lock(path)
 	if err != nil {
 		return err
 	}
-	defer lockFile.Close()
+	defer unlock(lockFile)
+
+	// Builds that predate the rename lock the legacy file, so hold that
+	// lock too while migrating away from it.
+	source := sourcePath(path)
+	if source != path {
+		legacyLock, err := lock(source)
+		if err != nil {
+			return err
+		}
+		defer unlock(legacyLock)
+	}
 
-	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+	m, version, raw, err := readFile(source)
+	if errors.Is(err, ErrCorrupt) {
+		m, err = recoverFile(source)
+		raw = nil
+	}
+	if err != nil {
 		return err
 	}
-	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+	if m == nil {
+		m = newMetadata()
+	}
+
+	if raw != nil && (version < SchemaVersion || source != path) {
+		if err := writeFile(fmt.Sprintf("%s.v%d.bak", source, version), raw); err != nil {
+			return fmt.Errorf("backing up metadata before migration: %w", err)
+		}
+	}
 
-	m, _ := LoadMetadata(projectPath)
 	if err := fn(m); err != nil {
 		return err
 	}
-	return SaveMetadata(projectPath, m)
+
+	m.Version = SchemaVersion
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(path, data); err != nil {
+		return err
+	}
+	if err := writeFile(backupPath(path), data); err != nil {
+		return err
+	}
+	if source != path {
+		return os.Remove(source)
+	}
+	return nil
 }
 
 func (m *Metadata) GetGistID(sessionID string) string {
@@ -117,51 +300,317 @@ func (m *Metadata) SetGistID(sessionID, gistID string) {
 	m.Sessions[sessionID] = s
 }
 
-func (m *Metadata) GetPrevSessionID(sessionID string) string {
+func (m *Metadata) GetShareID(sessionID, publisher string) string {
+	if publisher == "gist" {
+		return m.GetGistID(sessionID)
+	}
 	if s, ok := m.Sessions[sessionID]; ok {
-		return s.PrevSessionID
+		return s.Shares[publisher]
 	}
 	return ""
 }
 
-func (m *Metadata) GetNextSessionID(sessionID string) string {
+func (m *Metadata) SetShareID(sessionID, publisher, id string) {
+	if id == "" {
+		m.SetContentHash(sessionID, publisher, "")
+		m.SetShareFiles(sessionID, publisher, nil)
+		m.SetVisibility(sessionID, publisher, "")
+	}
+	if publisher == "gist" {
+		m.SetGistID(sessionID, id)
+		return
+	}
+	s := m.Sessions[sessionID]
+	if s.Shares == nil {
+		s.Shares = make(map[string]string)
+	}
+	if id == "" {
+		delete(s.Shares, publisher)
+	} else {
+		s.Shares[publisher] = id
+	}
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) GetContentHash(sessionID, publisher string) string {
 	if s, ok := m.Sessions[sessionID]; ok {
-		return s.NextSessionID
+		return s.ContentHashes[publisher]
 	}
 	return ""
 }
 
-func (m *Metadata) ResolveLatestSession(startSessionID string) (latestSession, prevSession string) {
-	if startSessionID == "" {
-		return "", ""
+func (m *Metadata) SetContentHash(sessionID, publisher, hash string) {
+	s, ok := m.Sessions[sessionID]
+	if !ok && hash == "" {
+		return
+	}
+	if s.ContentHashes == nil {
+		s.ContentHashes = make(map[string]string)
+	}
+	if hash == "" {
+		delete(s.ContentHashes, publisher)
+	} else {
+		s.ContentHashes[publisher] = hash
 	}
+	m.Sessions[sessionID] = s
+}
 
-	if _, ok := m.Sessions[startSessionID]; !ok {
-		return startSessionID, ""
+// GetShareFiles returns the names of the files uploaded for a share. It is
+// empty for shares made before threads could span several files.
+func (m *Metadata) GetShareFiles(sessionID, publisher string) []string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.ShareFiles[publisher]
 	}
+	return nil
+}
 
-	prev := ""
-	current := startSessionID
-	for {
-		next := m.GetNextSessionID(current)
-		if next == "" {
-			return current, prev
-		}
-		prev = current
-		current = next
+func (m *Metadata) SetShareFiles(sessionID, publisher string, files []string) {
+	s, ok := m.Sessions[sessionID]
+	if !ok && len(files) == 0 {
+		return
+	}
+	if s.ShareFiles == nil {
+		s.ShareFiles = make(map[string][]string)
+	}
+	if len(files) == 0 {
+		delete(s.ShareFiles, publisher)
+	} else {
+		s.ShareFiles[publisher] = files
+	}
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) GetCombined(sessionID, publisher string) Combined {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Combined[publisher]
+	}
+	return Combined{}
+}
+
+func (m *Metadata) SetCombined(sessionID, publisher string, c Combined) {
+	s, ok := m.Sessions[sessionID]
+	if !ok && c.ID == "" {
+		return
+	}
+	if s.Combined == nil {
+		s.Combined = make(map[string]Combined)
+	}
+	if c.ID == "" {
+		delete(s.Combined, publisher)
+	} else {
+		s.Combined[publisher] = c
+	}
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) GetShares(sessionID string) map[string]string {
+	s, ok := m.Sessions[sessionID]
+	if !ok {
+		return nil
+	}
+	shares := make(map[string]string, len(s.Shares)+1)
+	for publisher, id := range s.Shares {
+		shares[publisher] = id
+	}
+	if s.GistID != "" {
+		shares["gist"] = s.GistID
+	}
+	return shares
+}
+
+// GetVisibility returns the gist visibility a share was published with.
+// Each publisher has its own, since a session can be shared to several
+// GitHub hosts.
+func (m *Metadata) GetVisibility(sessionID, publisher string) string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Visibilities[publisher]
+	}
+	return ""
+}
+
+func (m *Metadata) SetVisibility(sessionID, publisher, visibility string) {
+	s, ok := m.Sessions[sessionID]
+	if !ok && visibility == "" {
+		return
+	}
+	if s.Visibilities == nil {
+		s.Visibilities = make(map[string]string)
+	}
+	if visibility == "" {
+		delete(s.Visibilities, publisher)
+	} else {
+		s.Visibilities[publisher] = visibility
+	}
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) IsAnonymized(sessionID string) bool {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Anonymize
+	}
+	return false
+}
+
+func (m *Metadata) SetAnonymized(sessionID string, anonymize bool) {
+	s := m.Sessions[sessionID]
+	s.Anonymize = anonymize
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) IsEncrypted(sessionID string) bool {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Encrypted
+	}
+	return false
+}
+
+func (m *Metadata) SetEncrypted(sessionID string, encrypted bool) {
+	s := m.Sessions[sessionID]
+	s.Encrypted = encrypted
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+// GetPassphraseCheck returns the verifier of the passphrase a session was
+// last encrypted with, which tells whether another passphrase matches it.
+func (m *Metadata) GetPassphraseCheck(sessionID string) string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.PassphraseCheck
+	}
+	return ""
+}
+
+func (m *Metadata) SetPassphraseCheck(sessionID, check string) {
+	s := m.Sessions[sessionID]
+	s.PassphraseCheck = check
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) IsBundled(sessionID string) bool {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Bundle
 	}
+	return false
 }
 
-func (m *Metadata) LinkSession(latestID, newID string) {
-	if latestID != "" {
-		latest := m.Sessions[latestID]
-		latest.NextSessionID = newID
-		latest.UpdatedAt = time.Now()
-		m.Sessions[latestID] = latest
+func (m *Metadata) SetBundled(sessionID string, bundle bool) {
+	s := m.Sessions[sessionID]
+	s.Bundle = bundle
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) HasRawJSONL(sessionID string) bool {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.RawJSONL
 	}
+	return false
+}
 
-	newSession := m.Sessions[newID]
-	newSession.PrevSessionID = latestID
-	newSession.UpdatedAt = time.Now()
-	m.Sessions[newID] = newSession
+func (m *Metadata) SetRawJSONL(sessionID string, raw bool) {
+	s := m.Sessions[sessionID]
+	s.RawJSONL = raw
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+// IsUnshared reports whether a session's share under publisher was deleted
+// with unshare, so syncing its neighbours must not publish it again there.
+func (m *Metadata) IsUnshared(sessionID, publisher string) bool {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Unshared[publisher]
+	}
+	return false
+}
+
+func (m *Metadata) SetUnshared(sessionID, publisher string, unshared bool) {
+	s, ok := m.Sessions[sessionID]
+	if !ok && !unshared {
+		return
+	}
+	if s.Unshared == nil {
+		s.Unshared = make(map[string]bool)
+	}
+	if unshared {
+		s.Unshared[publisher] = true
+	} else {
+		delete(s.Unshared, publisher)
+	}
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+// GetTitle returns the title set for a session, which takes precedence over
+// the one derived from its messages.
+func (m *Metadata) GetTitle(sessionID string) string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Title
+	}
+	return ""
+}
+
+func (m *Metadata) SetTitle(sessionID, title string) {
+	s := m.Sessions[sessionID]
+	s.Title = title
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+// GetNote returns the author's note shown at the top of a session's export.
+func (m *Metadata) GetNote(sessionID string) string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Note
+	}
+	return ""
+}
+
+func (m *Metadata) SetNote(sessionID, note string) {
+	s := m.Sessions[sessionID]
+	s.Note = note
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) GetTags(sessionID string) []string {
+	if s, ok := m.Sessions[sessionID]; ok {
+		return s.Tags
+	}
+	return nil
+}
+
+func (m *Metadata) HasTag(sessionID, tag string) bool {
+	return slices.Contains(m.GetTags(sessionID), tag)
+}
+
+// AddTags adds tags to a session, keeping them sorted and unique.
+func (m *Metadata) AddTags(sessionID string, tags ...string) {
+	s := m.Sessions[sessionID]
+	for _, tag := range tags {
+		if !slices.Contains(s.Tags, tag) {
+			s.Tags = append(s.Tags, tag)
+		}
+	}
+	sort.Strings(s.Tags)
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
+}
+
+func (m *Metadata) RemoveTags(sessionID string, tags ...string) {
+	s, ok := m.Sessions[sessionID]
+	if !ok {
+		return
+	}
+	var kept []string
+	for _, t := range s.Tags {
+		if !slices.Contains(tags, t) {
+			kept = append(kept, t)
+		}
+	}
+	s.Tags = kept
+	s.UpdatedAt = time.Now()
+	m.Sessions[sessionID] = s
 }
@@ -0,0 +1,150 @@
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SchemaVersion is the version of the metadata format this build reads and
+// writes. Bump it together with a new entry in migrations whenever the
+// format changes in a way older builds would mishandle.
+const SchemaVersion = 3
+
+// ErrNewerVersion is returned when a metadata file was written by a newer
+// build. It is still read on a best-effort basis but never written, since
+// that would drop the fields this build does not know about.
+var ErrNewerVersion = errors.New("metadata was written by a newer version of claude-coding")
+
+// migrations[n] upgrades a decoded metadata file from schema version n to
+// n+1. They work on the raw JSON so that they can handle shapes the
+// current types no longer describe.
+var migrations = []func(doc map[string]any) error{
+	migrateV0,
+	migrateV1,
+	migrateV2,
+}
+
+// migrateV0 upgrades the unversioned workbench-metadata.json format, which
+// only differs from version 1 in the file name and the missing version.
+func migrateV0(doc map[string]any) error {
+	sessions, _ := doc["sessions"].(map[string]any)
+	if sessions == nil {
+		sessions = make(map[string]any)
+	}
+	for id, s := range sessions {
+		if _, ok := s.(map[string]any); !ok {
+			delete(sessions, id)
+		}
+	}
+	doc["sessions"] = sessions
+	return nil
+}
+
+// migrateV1 replaces the prev/next pointers of version 1 with parent and
+// children lists. Every link made before version 2 came from /clear. Each
+// side keeps its own claim, so inconsistent links stay visible to the chain
+// checks.
+func migrateV1(doc map[string]any) error {
+	sessions, _ := doc["sessions"].(map[string]any)
+	for _, raw := range sessions {
+		s, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if prev, _ := s["prev_session_id"].(string); prev != "" {
+			s["parents"] = []any{map[string]any{"session_id": prev, "kind": LinkClear}}
+		}
+		if next, _ := s["next_session_id"].(string); next != "" {
+			s["children"] = []any{map[string]any{"session_id": next, "kind": LinkClear}}
+		}
+		delete(s, "prev_session_id")
+		delete(s, "next_session_id")
+	}
+	return nil
+}
+
+// migrateV2 moves the gist visibility and the unshared flag, kept once per
+// session in version 2, onto the share keys they apply to. Version 2 did
+// not record which share was deleted, so an unshared flag is kept for the
+// default gist share, unless the session is shared there again.
+func migrateV2(doc map[string]any) error {
+	sessions, _ := doc["sessions"].(map[string]any)
+	for _, raw := range sessions {
+		s, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		if unshared, ok := s["unshared"].(bool); ok {
+			delete(s, "unshared")
+			if id, _ := s["gist_id"].(string); unshared && id == "" {
+				s["unshared"] = map[string]any{"gist": true}
+			}
+		}
+		visibility, _ := s["visibility"].(string)
+		delete(s, "visibility")
+		if visibility == "" {
+			continue
+		}
+		visibilities := make(map[string]any)
+		if id, _ := s["gist_id"].(string); id != "" {
+			visibilities["gist"] = visibility
+		}
+		shares, _ := s["shares"].(map[string]any)
+		for key := range shares {
+			if strings.HasPrefix(key, "gist:") {
+				visibilities[key] = visibility
+			}
+		}
+		if len(visibilities) > 0 {
+			s["visibilities"] = visibilities
+		}
+	}
+	return nil
+}
+
+// decode parses a metadata file, migrating it to SchemaVersion if needed,
+// and returns the version the file was written with.
+func decode(path string, data []byte) (*Metadata, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil || doc == nil {
+		if err == nil {
+			err = errors.New("not a JSON object")
+		}
+		return nil, 0, fmt.Errorf("%s: %w: %v", path, ErrCorrupt, err)
+	}
+
+	version := 0
+	if v, ok := doc["version"]; ok {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != float64(int(n)) {
+			return nil, 0, fmt.Errorf("%s: %w: invalid version %v", path, ErrCorrupt, v)
+		}
+		version = int(n)
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, version, fmt.Errorf("%s: migrating from version %d: %w", path, v, err)
+		}
+	}
+	doc["version"] = max(version, SchemaVersion)
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, err
+	}
+	var m Metadata
+	if err := json.Unmarshal(migrated, &m); err != nil {
+		return nil, version, fmt.Errorf("%s: %w: %v", path, ErrCorrupt, err)
+	}
+	if m.Sessions == nil {
+		m.Sessions = make(map[string]Session)
+	}
+
+	if version > SchemaVersion {
+		return &m, version, fmt.Errorf("%s: %w (schema version %d, this build supports %d); upgrade claude-coding", path, ErrNewerVersion, version, SchemaVersion)
+	}
+	return &m, version, nil
+}
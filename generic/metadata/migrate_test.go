@@ -0,0 +1,198 @@
+package metadata
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// The same history in every schema version: a was cleared into b, a is
+// shared as a public gist, also under the "gist:work" account, and b's
+// gist was deleted.
+const (
+	metadataV0 = `{"sessions": {
+		"a": {"next_session_id": "b", "gist_id": "g1", "shares": {"gist:work": "w1"}, "visibility": "public", "updated_at": "2025-01-01T00:00:00Z"},
+		"b": {"prev_session_id": "a", "unshared": true, "updated_at": "2025-01-01T00:00:00Z"},
+		"bogus": "not a session"
+	}}`
+	metadataV1 = `{"version": 1, "sessions": {
+		"a": {"next_session_id": "b", "gist_id": "g1", "shares": {"gist:work": "w1"}, "visibility": "public", "updated_at": "2025-01-01T00:00:00Z"},
+		"b": {"prev_session_id": "a", "unshared": true, "updated_at": "2025-01-01T00:00:00Z"}
+	}}`
+	metadataV2 = `{"version": 2, "sessions": {
+		"a": {"children": [{"session_id": "b", "kind": "clear"}], "gist_id": "g1", "shares": {"gist:work": "w1"}, "visibility": "public", "updated_at": "2025-01-01T00:00:00Z"},
+		"b": {"parents": [{"session_id": "a", "kind": "clear"}], "unshared": true, "updated_at": "2025-01-01T00:00:00Z"}
+	}}`
+	metadataV3 = `{"version": 3, "sessions": {
+		"a": {"children": [{"session_id": "b", "kind": "clear"}], "gist_id": "g1", "shares": {"gist:work": "w1"}, "visibilities": {"gist": "public", "gist:work": "public"}, "updated_at": "2025-01-01T00:00:00Z"},
+		"b": {"parents": [{"session_id": "a", "kind": "clear"}], "unshared": {"gist": true}, "updated_at": "2025-01-01T00:00:00Z"}
+	}}`
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		data   string
+		backup string // the copy of the file kept before migrating it
+	}{
+		{"legacy v0", LegacyFileName, metadataV0, LegacyFileName + ".v0.bak"},
+		{"legacy v1", LegacyFileName, metadataV1, LegacyFileName + ".v1.bak"},
+		{"v1", FileName, metadataV1, FileName + ".v1.bak"},
+		{"v2", FileName, metadataV2, FileName + ".v2.bak"},
+		{"current", FileName, metadataV3, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			// Reading migrates in memory only.
+			m, err := LoadMetadataDir(dir)
+			if err != nil {
+				t.Fatalf("LoadMetadataDir: %v", err)
+			}
+			checkMigrated(t, m)
+			if got := files(t, dir); !slices.Equal(got, []string{tt.file}) {
+				t.Errorf("after LoadMetadataDir the directory holds %v, want only %s", got, tt.file)
+			}
+
+			if err := WithLockDir(dir, func(m *Metadata) error {
+				checkMigrated(t, m)
+				return nil
+			}); err != nil {
+				t.Fatalf("WithLockDir: %v", err)
+			}
+
+			want := []string{FileName, FileName + ".bak", FileName + ".lock"}
+			if tt.file == LegacyFileName {
+				want = append(want, LegacyFileName+".lock")
+			}
+			if tt.backup != "" {
+				want = append(want, tt.backup)
+				backup, err := os.ReadFile(filepath.Join(dir, tt.backup))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(backup) != tt.data {
+					t.Errorf("%s = %s, want the file as it was before migrating", tt.backup, backup)
+				}
+			}
+			slices.Sort(want)
+			if got := files(t, dir); !slices.Equal(got, want) {
+				t.Errorf("after WithLockDir the directory holds %v, want %v", got, want)
+			}
+
+			written, err := os.ReadFile(filepath.Join(dir, FileName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			backup, err := os.ReadFile(filepath.Join(dir, FileName+".bak"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(backup) != string(written) {
+				t.Errorf("%s.bak differs from the file written", FileName)
+			}
+
+			m, err = LoadMetadataDir(dir)
+			if err != nil {
+				t.Fatalf("LoadMetadataDir after migrating: %v", err)
+			}
+			if m.Version != SchemaVersion {
+				t.Errorf("written version = %d, want %d", m.Version, SchemaVersion)
+			}
+			checkMigrated(t, m)
+		})
+	}
+}
+
+func checkMigrated(t *testing.T, m *Metadata) {
+	t.Helper()
+	if got := slices.Sorted(maps.Keys(m.Sessions)); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("sessions = %v, want [a b]", got)
+	}
+	if got := formatLinks(m.GetChildren("a")); got != "b" {
+		t.Errorf("children of a = %q, want b", got)
+	}
+	if got := formatLinks(m.GetParents("b")); got != "a" {
+		t.Errorf("parents of b = %q, want a", got)
+	}
+	if got := m.LinkKind("b"); got != LinkClear {
+		t.Errorf("link kind of b = %q, want %q", got, LinkClear)
+	}
+	for _, key := range []string{"gist", "gist:work"} {
+		if got := m.GetVisibility("a", key); got != "public" {
+			t.Errorf("visibility of a on %s = %q, want public", key, got)
+		}
+	}
+	if !m.IsUnshared("b", "gist") {
+		t.Error("b is not recorded as unshared from gist")
+	}
+	if m.IsUnshared("a", "gist") || m.IsUnshared("b", "gist:work") {
+		t.Error("unshared is recorded for a share that was not deleted")
+	}
+}
+
+func TestMigrateV2Unshared(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		want    map[string]bool
+	}{
+		{"unshared", `{"unshared": true}`, map[string]bool{"gist": true}},
+		{"shared again", `{"unshared": true, "gist_id": "g2"}`, nil},
+		{"never unshared", `{"unshared": false}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _, err := decode("test", []byte(`{"version": 2, "sessions": {"s": `+tt.session+`}}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := m.Sessions["s"].Unshared
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("unshared = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, FileName)
+	data := `{"version": 99, "sessions": {"a": {"updated_at": "2025-01-01T00:00:00Z"}}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WithLockDir(dir, func(*Metadata) error { return nil }); err == nil {
+		t.Fatal("WithLockDir wrote metadata from a newer version")
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("metadata from a newer version was changed to %s", got)
+	}
+}
+
+// files lists the names in dir, sorted.
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
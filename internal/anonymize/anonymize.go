@@ -0,0 +1,252 @@
+package anonymize
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+const (
+	DefaultAuthor   = "Anonymous"
+	homeReplacement = "~"
+	userReplacement = "user"
+	hostReplacement = "host"
+	remotePrefix    = "https://git.example.com/"
+)
+
+var (
+	emailRe  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	remoteRe = regexp.MustCompile(`(?:ssh://)?git@[\w.-]+[:/][\w.-]+/[\w.-]+?(?:\.git)?\b|https?://(?:[\w.-]+@)?(?:github\.com|gitlab\.com|bitbucket\.org)/[\w.-]+/[\w.-]+?(?:\.git)?\b|https?://(?:[\w.-]+@)?[\w.-]+/[\w./-]+\.git\b`)
+)
+
+// Anonymizer replaces identifying strings with placeholders. The same
+// email or remote gets the same pseudonym for the Anonymizer's lifetime, so
+// one Anonymizer should be shared by every page of a share or chain.
+type Anonymizer struct {
+	Author string
+
+	mu       sync.Mutex
+	literals []literal
+	words    []*regexp.Regexp
+	wordRepl []string
+	emails   map[string]string
+	remotes  map[string]string
+}
+
+// literal is a string replaced as it is, or only as a whole word.
+type literal struct {
+	from string
+	to   string
+	word bool
+}
+
+// identity is what identifies the local user and machine.
+type identity struct {
+	Home     string
+	UserHome string
+	Username string
+	Name     string
+	Hostname string
+}
+
+func New() *Anonymizer {
+	var id identity
+	id.Home, _ = os.UserHomeDir()
+	if u, err := user.Current(); err == nil {
+		id.UserHome, id.Username, id.Name = u.HomeDir, u.Username, u.Name
+	}
+	id.Hostname, _ = os.Hostname()
+	return newAnonymizer(id)
+}
+
+func newAnonymizer(id identity) *Anonymizer {
+	a := &Anonymizer{
+		Author:  DefaultAuthor,
+		emails:  make(map[string]string),
+		remotes: make(map[string]string),
+	}
+
+	// A home directory is replaced as a word so that /home/dev leaves
+	// /home/devon alone.
+	for _, home := range []string{id.Home, id.UserHome} {
+		a.addWord(home, homeReplacement)
+	}
+	// A one-word full name that is the username, such as "root", is left
+	// to addUsername, which knows where a username appears.
+	if id.Name != "" && !strings.EqualFold(id.Name, id.Username) {
+		a.addWord(id.Name, a.Author)
+	}
+	a.addUsername(id.Username)
+	a.addHostname(id.Hostname)
+
+	sort.SliceStable(a.literals, func(i, j int) bool {
+		return len(a.literals[i].from) > len(a.literals[j].from)
+	})
+	return a
+}
+
+func (a *Anonymizer) addWord(word, to string) {
+	if len(word) < 3 {
+		return
+	}
+	a.add(literal{from: word, to: to, word: true})
+}
+
+func (a *Anonymizer) add(l literal) {
+	for _, existing := range a.literals {
+		if existing.from == l.from {
+			return
+		}
+	}
+	a.literals = append(a.literals, l)
+}
+
+// addUsername replaces the username only where it is clearly one: as the
+// directory under /home or /Users, and before @ as in user@host. Usernames
+// such as "dev" or "admin" are ordinary words elsewhere.
+func (a *Anonymizer) addUsername(username string) {
+	if len(username) < 3 {
+		return
+	}
+	quoted := regexp.QuoteMeta(username)
+	a.addPattern(`((?:/home|/Users|\\Users)[/\\])`+quoted+`\b`, "${1}"+userReplacement)
+	a.addPattern(`\b`+quoted+`@`, userReplacement+"@")
+}
+
+// addHostname replaces the hostname. One that could be an ordinary word,
+// such as "build" or "dev", is only replaced where it is clearly a host:
+// after @ and before a local domain. Names with a dot, hyphen or digit,
+// such as "ip-10-0-0-1" or "dev-box.corp", are replaced anywhere.
+func (a *Anonymizer) addHostname(host string) {
+	names := []string{host}
+	if short, _, ok := strings.Cut(host, "."); ok {
+		names = append(names, short)
+	}
+	for _, name := range names {
+		if len(name) < 3 {
+			continue
+		}
+		if strings.ContainsAny(name, ".-0123456789") {
+			a.addWord(name, hostReplacement)
+			continue
+		}
+		quoted := regexp.QuoteMeta(name)
+		a.addPattern(`@`+quoted+`\b`, "@"+hostReplacement)
+		a.addPattern(`\b`+quoted+`(\.(?:local|localdomain|lan|home|internal)\b)`, hostReplacement+"${1}")
+	}
+}
+
+// addPattern adds a regexp whose matches are replaced by to, which may
+// refer to submatches.
+func (a *Anonymizer) addPattern(pattern, to string) {
+	a.words = append(a.words, regexp.MustCompile(pattern))
+	a.wordRepl = append(a.wordRepl, to)
+}
+
+func (a *Anonymizer) String(s string) string {
+	if s == "" {
+		return s
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s = remoteRe.ReplaceAllStringFunc(s, a.remote)
+	s = emailRe.ReplaceAllStringFunc(s, a.email)
+
+	for _, l := range a.literals {
+		if l.word {
+			s = replaceWord(s, l.from, l.to)
+		} else {
+			s = strings.ReplaceAll(s, l.from, l.to)
+		}
+	}
+	for i, re := range a.words {
+		s = re.ReplaceAllString(s, a.wordRepl[i])
+	}
+	return s
+}
+
+// replaceWord replaces the occurrences of word that are not part of a
+// longer word. Unlike \b in a regexp, it treats non-ASCII letters as
+// letters, so names such as "José" are matched too.
+func replaceWord(s, word, to string) string {
+	var b strings.Builder
+	start := 0
+	for off := 0; off < len(s); {
+		i := strings.Index(s[off:], word)
+		if i < 0 {
+			break
+		}
+		i += off
+		end := i + len(word)
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+		if isWordRune(before) || isWordRune(after) {
+			off = i + 1
+			continue
+		}
+		b.WriteString(s[start:i])
+		b.WriteString(to)
+		start, off = end, end
+	}
+	if start == 0 {
+		return s
+	}
+	b.WriteString(s[start:])
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func (a *Anonymizer) remote(match string) string {
+	if pseudo, ok := a.remotes[match]; ok {
+		return pseudo
+	}
+	// Text anonymized before, such as a linked session's title, keeps
+	// its pseudonyms.
+	if strings.HasPrefix(match, remotePrefix) {
+		return match
+	}
+	pseudo := fmt.Sprintf("%srepo-%d.git", remotePrefix, len(a.remotes)+1)
+	a.remotes[match] = pseudo
+	return pseudo
+}
+
+func (a *Anonymizer) email(match string) string {
+	key := strings.ToLower(match)
+	if pseudo, ok := a.emails[key]; ok {
+		return pseudo
+	}
+	if strings.HasSuffix(key, "@example.com") {
+		return match
+	}
+	pseudo := fmt.Sprintf("user-%d@example.com", len(a.emails)+1)
+	a.emails[key] = pseudo
+	return pseudo
+}
+
+func (a *Anonymizer) Messages(messages []parser.Message) []parser.Message {
+	result := make([]parser.Message, len(messages))
+	for i, msg := range messages {
+		blocks := make([]parser.ContentBlock, len(msg.Blocks))
+		for j, block := range msg.Blocks {
+			block.Content = a.String(block.Content)
+			block.ToolInput = a.String(block.ToolInput)
+			block.ToolName = a.String(block.ToolName)
+			blocks[j] = block
+		}
+		msg.Blocks = blocks
+		result[i] = msg
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package anonymize
+
+import "testing"
+
+func TestString(t *testing.T) {
+	dev := identity{Home: "/home/dev", UserHome: "/home/dev", Username: "dev", Name: "Jane Doe", Hostname: "build"}
+	root := identity{Home: "/root", UserHome: "/root", Username: "root", Name: "root", Hostname: "dev-box.corp.example"}
+	mac := identity{Home: "/Users/josé", UserHome: "/Users/josé", Username: "josé", Name: "José Núñez", Hostname: "Joses-MacBook-Pro.local"}
+
+	tests := []struct {
+		name string
+		id   identity
+		in   string
+		want string
+	}{
+		{"home path", dev, "open /home/dev/src/app/main.go", "open ~/src/app/main.go"},
+		{"home alone", dev, "cd /home/dev", "cd ~"},
+		{"other home", dev, "ls /home/devon/src", "ls /home/devon/src"},
+		{"windows home", dev, `C:\Users\dev\src`, `C:\Users\user\src`},
+		{"mac home", mac, "/Users/josé/Projects/x", "~/Projects/x"},
+		{"user at host", dev, "ssh dev@build", "ssh user@host"},
+		{"prompt", root, "root@dev-box:~# ls", "user@host:~# ls"},
+		{"username as a word", dev, "start the dev server; cat /dev/null", "start the dev server; cat /dev/null"},
+		{"full name", dev, "Signed-off-by: Jane Doe", "Signed-off-by: Anonymous"},
+		{"full name inside a word", dev, "Jane Doesburg", "Jane Doesburg"},
+		{"non-ASCII full name", mac, "Author: José Núñez.", "Author: Anonymous."},
+		{"one-word full name", root, "run chroot as root", "run chroot as root"},
+		{"emails", dev, "mail jane@corp.io, bob@corp.io and Jane@corp.io", "mail user-1@example.com, user-2@example.com and user-1@example.com"},
+		{"example email", dev, "ci@example.com", "ci@example.com"},
+		{"ssh remote", dev, "git@github.com:acme/secret.git", "https://git.example.com/repo-1.git"},
+		{"https remote", dev, "clone https://github.com/acme/secret", "clone https://git.example.com/repo-1.git"},
+		{"self-hosted remote", dev, "https://git.corp.io/team/app.git", "https://git.example.com/repo-1.git"},
+		{"plain hostname as a word", dev, "run the build step", "run the build step"},
+		{"plain hostname in a domain", dev, "ping build.local", "ping host.local"},
+		{"distinctive hostname", root, "connected to dev-box.corp.example", "connected to host"},
+		{"distinctive short hostname", root, "on dev-box now", "on host now"},
+		{"mac hostname", mac, "Joses-MacBook-Pro.local and Joses-MacBook-Pro", "host and host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newAnonymizer(tt.id)
+			if got := a.String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringIsStable(t *testing.T) {
+	a := newAnonymizer(identity{Home: "/home/dev", Username: "dev", Name: "Jane Doe", Hostname: "build"})
+	in := "Jane Doe (jane@corp.io) pushed /home/dev/app to git@github.com:acme/app.git from dev@build"
+
+	once := a.String(in)
+	if twice := a.String(once); twice != once {
+		t.Errorf("anonymizing again changed %q to %q", once, twice)
+	}
+	if again := a.String(in); again != once {
+		t.Errorf("the same text got %q, then %q", once, again)
+	}
+}
@@ -0,0 +1,118 @@
+package converter
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+// ChainSection is one session of a combined export. Kind is how the
+// session was started from the one before it.
+type ChainSection struct {
+	SessionID string
+	Title     string
+	Kind      string
+	Note      string
+	Messages  []parser.Message
+}
+
+// ConvertChain renders several linked sessions as one document: a table of
+// contents, then each session's messages introduced by a divider with its
+// title. Like ConvertPages, the document is split into pages of at most
+// maxBytes when it does not fit, and the table of contents on every page
+// links into the others through cfg.PageURL.
+func ConvertChain(sections []ChainSection, cfg Config, maxBytes int) []string {
+	currentProjectPath = cfg.ProjectPath
+
+	// The table of contents is measured with links to other pages, which is
+	// its longest form.
+	longest := buildChainTOCHTML(sections, func(int) string { return "" })
+	if cfg.PageURL != nil {
+		longest = buildChainTOCHTML(sections, func(int) string { return cfg.PageURL(len(sections)) })
+	}
+	limit := chunkLimit(cfg, longest, maxBytes)
+
+	var rendered []string
+	first := make([]int, len(sections))
+	for i, section := range sections {
+		messages := mergeToolResults(section.Messages)
+		messages = mergeBashMessages(messages)
+
+		first[i] = len(rendered)
+		divider := buildChainDividerHTML(i+1, len(sections), section)
+		rendered = append(rendered, divider)
+		if note := buildNoteHTML(section.Note); note != "" {
+			rendered = append(rendered, note)
+		}
+		for _, msg := range messages {
+			rendered = append(rendered, renderMessageWithin(msg, cfg, limit))
+		}
+	}
+
+	starts := []int{0}
+	if maxBytes > 0 {
+		starts = paginate(cfg, rendered, longest, maxBytes)
+	}
+	sectionPage := make([]int, len(sections))
+	for i, chunk := range first {
+		for page, start := range starts {
+			if chunk >= start {
+				sectionPage[i] = page + 1
+			}
+		}
+	}
+
+	return renderPages(cfg, rendered, starts, func(page int) string {
+		return buildChainTOCHTML(sections, func(i int) string {
+			if sectionPage[i] == page || cfg.PageURL == nil {
+				return ""
+			}
+			return cfg.PageURL(sectionPage[i])
+		})
+	})
+}
+
+func chainAnchor(sessionID string) string {
+	return "session-" + sessionID
+}
+
+// buildChainTOCHTML lists the sections, linking each to its divider on the
+// page returned by pageURL, or on the same page when that is empty.
+func buildChainTOCHTML(sections []ChainSection, pageURL func(section int) string) string {
+	var toc strings.Builder
+	toc.WriteString(`<nav class="chain-toc"><h2>Sessions</h2><ol>`)
+	for i, section := range sections {
+		href := pageURL(i) + "#" + chainAnchor(section.SessionID)
+		toc.WriteString(`<li>` + linkKindLabel(section.Kind) + `<a href="` + html.EscapeString(href) + `">` + html.EscapeString(section.Title) + `</a>`)
+		if start := sectionStart(section); !start.IsZero() {
+			toc.WriteString(` <time datetime="` + start.Format(time.RFC3339) + `">` + start.Format("Jan 2, 2006 15:04") + `</time>`)
+		}
+		toc.WriteString(`</li>`)
+	}
+	toc.WriteString(`</ol></nav>`)
+	return toc.String()
+}
+
+func buildChainDividerHTML(n, total int, section ChainSection) string {
+	var div strings.Builder
+	div.WriteString(`<div class="chain-divider" id="` + html.EscapeString(chainAnchor(section.SessionID)) + `">`)
+	div.WriteString(fmt.Sprintf(`<span class="chain-label">%sSession %d of %d</span>`, linkKindLabel(section.Kind), n, total))
+	div.WriteString(`<h2>` + html.EscapeString(section.Title) + `</h2>`)
+	if start := sectionStart(section); !start.IsZero() {
+		div.WriteString(`<time datetime="` + start.Format(time.RFC3339) + `">` + start.Format("Jan 2, 2006 15:04") + `</time>`)
+	}
+	div.WriteString(`</div>`)
+	return div.String()
+}
+
+func sectionStart(section ChainSection) time.Time {
+	for _, msg := range section.Messages {
+		if !msg.Timestamp.IsZero() {
+			return msg.Timestamp
+		}
+	}
+	return time.Time{}
+}
@@ -5,45 +5,187 @@ import (
 	"fmt"
 	"html"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/priyanshujain/claude-coding/internal/parser"
 	"github.com/priyanshujain/claude-coding/internal/template"
 )
 
 type Config struct {
-	Title          string
-	Username       string
-	UserInitials   string
-	ProjectPath    string
+	Title        string
+	Username     string
+	UserInitials string
+	ProjectPath  string
+	// Note is the author's note shown above the first message.
+	Note           string
 	PrevSessionURL string
 	NextSessionURL string
+	// LinkKind is how the session was started from the one before it.
+	LinkKind string
+	// Ancestors are the published sessions this one continues from, oldest
+	// first, and Branches the published sessions continuing from it, most
+	// recent last. Together they are shown as a trail above the thread.
+	Ancestors []SessionLink
+	Branches  []SessionLink
+	// Index lists every published session linked to this one, with the
+	// current session marked, for a dropdown of the whole history.
+	Index []SessionLink
+	// PageURL returns the URL of a page (numbered from 1) when a thread is
+	// split across several pages. Without it pages are not linked.
+	PageURL func(page int) string
+}
+
+// SessionLink is a linked session in the navigation. Kind is how the
+// session was started from the one before it: clear, resume or fork.
+type SessionLink struct {
+	Title   string
+	URL     string
+	Kind    string
+	Date    time.Time
+	Current bool
 }
 
 var currentProjectPath string
 
 func Convert(messages []parser.Message, cfg Config) string {
+	return ConvertPages(messages, cfg, 0)[0]
+}
+
+// ConvertPages renders the thread as pages of at most maxBytes each, or as a
+// single page when maxBytes is 0 or the thread fits. Every page repeats the
+// header and session navigation and links to the other pages. A message too
+// big for a page is cut short with a truncation note.
+func ConvertPages(messages []parser.Message, cfg Config, maxBytes int) []string {
 	currentProjectPath = cfg.ProjectPath
 	messages = mergeToolResults(messages)
 	messages = mergeBashMessages(messages)
 
-	var messagesHTML strings.Builder
+	navHTML := buildNavHTML(cfg.PrevSessionURL, cfg.NextSessionURL) + buildTrailHTML(cfg) + buildIndexHTML(cfg.Index)
+
+	limit := chunkLimit(cfg, navHTML, maxBytes)
+	var rendered []string
+	if note := buildNoteHTML(cfg.Note); note != "" {
+		rendered = append(rendered, note)
+	}
 	for _, msg := range messages {
-		messagesHTML.WriteString(renderMessage(msg, cfg))
+		rendered = append(rendered, renderMessageWithin(msg, cfg, limit))
+	}
+
+	if maxBytes <= 0 {
+		return []string{renderPage(cfg, navHTML, strings.Join(rendered, ""))}
+	}
+
+	return renderPages(cfg, rendered, paginate(cfg, rendered, navHTML, maxBytes), func(int) string { return navHTML })
+}
+
+// chunkLimit is the most a single message may take on a page of maxBytes
+// with the navigation navHTML. Half of the room left for messages keeps
+// every message clear of the page links, however many pages there are.
+func chunkLimit(cfg Config, navHTML string, maxBytes int) int {
+	if maxBytes <= 0 {
+		return 0
+	}
+	return (maxBytes - len(renderPage(cfg, navHTML, ""))) / 2
+}
+
+// paginate splits the rendered chunks into pages that stay within maxBytes
+// once laid out with navHTML, which must be the longest navigation any page
+// gets, and with the page links above and below the messages.
+func paginate(cfg Config, rendered []string, navHTML string, maxBytes int) []int {
+	frame := len(renderPage(cfg, navHTML, ""))
+	pages := 1
+	for {
+		// More pages mean longer page links and so less room for messages;
+		// repeat until the page count stops growing.
+		reserve := 0
+		for page := 1; pages > 1 && page <= pages; page++ {
+			reserve = max(reserve, 2*len(buildPageNavHTML(page, pages, cfg.PageURL)))
+		}
+		starts := pageStarts(rendered, maxBytes-frame-reserve)
+		if len(starts) <= pages {
+			return starts
+		}
+		pages = len(starts)
+	}
+}
+
+// pageStarts splits the rendered chunks into pages of at most budget bytes
+// where possible and returns the index of the first chunk of each page.
+func pageStarts(rendered []string, budget int) []int {
+	starts := []int{0}
+	size := 0
+	for i, r := range rendered {
+		if size > 0 && size+len(r) > budget {
+			starts = append(starts, i)
+			size = 0
+		}
+		size += len(r)
 	}
+	return starts
+}
 
-	navHTML := buildNavHTML(cfg.PrevSessionURL, cfg.NextSessionURL)
+// renderPages lays out the chunks on the pages starting at starts, each
+// with the navigation returned by nav and links to the other pages.
+func renderPages(cfg Config, rendered []string, starts []int, nav func(page int) string) []string {
+	if len(starts) == 1 {
+		return []string{renderPage(cfg, nav(1), strings.Join(rendered, ""))}
+	}
+	pages := make([]string, len(starts))
+	for i, start := range starts {
+		end := len(rendered)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		pageNav := buildPageNavHTML(i+1, len(starts), cfg.PageURL)
+		pages[i] = renderPage(cfg, nav(i+1)+pageNav, strings.Join(rendered[start:end], "")+pageNav)
+	}
+	return pages
+}
 
+func renderPage(cfg Config, navHTML, messagesHTML string) string {
 	result := template.HTMLTemplate
 	result = strings.ReplaceAll(result, "TITLE_PLACEHOLDER", html.EscapeString(cfg.Title))
 	result = strings.ReplaceAll(result, "USERNAME_PLACEHOLDER", html.EscapeString(cfg.Username))
 	result = strings.ReplaceAll(result, "INITIALS_PLACEHOLDER", html.EscapeString(cfg.UserInitials))
 	result = strings.ReplaceAll(result, "NAV_PLACEHOLDER", navHTML)
-	result = strings.ReplaceAll(result, "MESSAGES_PLACEHOLDER", messagesHTML.String())
+	result = strings.ReplaceAll(result, "MESSAGES_PLACEHOLDER", messagesHTML)
 
 	return result
 }
 
+func buildPageNavHTML(page, pages int, pageURL func(int) string) string {
+	link := func(n int, label, class string) string {
+		if pageURL == nil {
+			return `<span class="` + class + `">` + label + `</span>`
+		}
+		return `<a href="` + html.EscapeString(pageURL(n)) + `" class="` + class + `">` + label + `</a>`
+	}
+
+	var nav strings.Builder
+	nav.WriteString(`<nav class="page-nav">`)
+	if page > 1 {
+		nav.WriteString(link(page-1, "← Previous Page", "page-prev"))
+	}
+	nav.WriteString(`<span class="page-list">`)
+	for n := 1; n <= pages; n++ {
+		if n == page {
+			nav.WriteString(fmt.Sprintf(`<span class="page-current">%d</span>`, n))
+		} else {
+			nav.WriteString(link(n, fmt.Sprint(n), "page-link"))
+		}
+	}
+	nav.WriteString(`</span>`)
+	if page < pages {
+		nav.WriteString(link(page+1, "Next Page →", "page-next"))
+	}
+	nav.WriteString(`</nav>`)
+	return nav.String()
+}
+
 func buildNavHTML(prevURL, nextURL string) string {
 	if prevURL == "" && nextURL == "" {
 		return ""
@@ -66,6 +208,108 @@ func buildNavHTML(prevURL, nextURL string) string {
 	return nav.String()
 }
 
+// buildTrailHTML renders the linked sessions as a breadcrumb from the first
+// session to this one. A single continuation extends the trail; several
+// are listed as branches below it.
+func buildTrailHTML(cfg Config) string {
+	if len(cfg.Ancestors) == 0 && len(cfg.Branches) == 0 {
+		return ""
+	}
+
+	crumb := func(l SessionLink) string {
+		return `<li>` + linkKindLabel(l.Kind) + `<a href="` + html.EscapeString(l.URL) + `">` + html.EscapeString(l.Title) + `</a></li>`
+	}
+
+	var nav strings.Builder
+	nav.WriteString(`<nav class="session-trail"><ol>`)
+	for _, l := range cfg.Ancestors {
+		nav.WriteString(crumb(l))
+	}
+	nav.WriteString(`<li class="trail-current">` + linkKindLabel(cfg.LinkKind) + html.EscapeString(cfg.Title) + `</li>`)
+	if len(cfg.Branches) == 1 {
+		nav.WriteString(crumb(cfg.Branches[0]))
+	}
+	nav.WriteString(`</ol>`)
+	if len(cfg.Branches) > 1 {
+		nav.WriteString(`<ul class="trail-branches">`)
+		for _, l := range cfg.Branches {
+			nav.WriteString(crumb(l))
+		}
+		nav.WriteString(`</ul>`)
+	}
+	nav.WriteString(`</nav>`)
+	return nav.String()
+}
+
+var paragraphBreakRe = regexp.MustCompile(`\n\s*\n`)
+
+// buildNoteHTML renders the author's note, keeping its paragraphs.
+func buildNoteHTML(note string) string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return ""
+	}
+
+	var aside strings.Builder
+	aside.WriteString(`<aside class="author-note"><strong>Author's note</strong>`)
+	for _, paragraph := range paragraphBreakRe.Split(note, -1) {
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(line)
+		}
+		aside.WriteString(`<p>` + strings.Join(lines, `<br>`) + `</p>`)
+	}
+	aside.WriteString(`</aside>`)
+	return aside.String()
+}
+
+// buildIndexHTML renders every linked session as a collapsed list, with the
+// current one highlighted.
+func buildIndexHTML(index []SessionLink) string {
+	if len(index) == 0 {
+		return ""
+	}
+
+	position := 0
+	for i, l := range index {
+		if l.Current {
+			position = i + 1
+		}
+	}
+
+	var nav strings.Builder
+	nav.WriteString(`<details class="session-index"><summary>`)
+	if position > 0 {
+		nav.WriteString(fmt.Sprintf("Session %d of %d", position, len(index)))
+	} else {
+		nav.WriteString(fmt.Sprintf("%d sessions", len(index)))
+	}
+	nav.WriteString(`</summary><ol>`)
+	for _, l := range index {
+		if l.Current {
+			nav.WriteString(`<li class="index-current" aria-current="page">` + linkKindLabel(l.Kind) + `<span>` + html.EscapeString(l.Title) + `</span>`)
+		} else {
+			nav.WriteString(`<li>` + linkKindLabel(l.Kind) + `<a href="` + html.EscapeString(l.URL) + `">` + html.EscapeString(l.Title) + `</a>`)
+		}
+		if !l.Date.IsZero() {
+			nav.WriteString(` <time datetime="` + l.Date.Format(time.RFC3339) + `">` + l.Date.Format("Jan 2, 2006 15:04") + `</time>`)
+		}
+		nav.WriteString(`</li>`)
+	}
+	nav.WriteString(`</ol></details>`)
+	return nav.String()
+}
+
+func linkKindLabel(kind string) string {
+	switch kind {
+	case "resume":
+		return `<span class="link-kind">resumed</span>`
+	case "fork":
+		return `<span class="link-kind">forked</span>`
+	}
+	return ""
+}
+
 func mergeBashMessages(messages []parser.Message) []parser.Message {
 	var result []parser.Message
 	for i := 0; i < len(messages); i++ {
@@ -171,6 +415,50 @@ func renderMessage(msg parser.Message, cfg Config) string {
 </div>`
 }
 
+// renderMessageWithin renders msg in at most limit bytes when limit is
+// positive. A message too big for a page has its longest text and tool
+// output cut short, with a note saying how much was left out.
+func renderMessageWithin(msg parser.Message, cfg Config, limit int) string {
+	r := renderMessage(msg, cfg)
+	if limit <= 0 || len(r) <= limit {
+		return r
+	}
+
+	msg.Blocks = slices.Clone(msg.Blocks)
+	type field struct {
+		text *string
+		orig string
+	}
+	var fields []field
+	for i := range msg.Blocks {
+		b := &msg.Blocks[i]
+		fields = append(fields, field{&b.Content, b.Content}, field{&b.ToolInput, b.ToolInput})
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return len(fields[i].orig) > len(fields[j].orig) })
+
+	for _, f := range fields {
+		keep := len(f.orig)
+		for len(r) > limit && keep > 0 {
+			keep = max(0, keep-(len(r)-limit))
+			*f.text = truncateText(f.orig, keep)
+			r = renderMessage(msg, cfg)
+		}
+		if len(r) <= limit {
+			break
+		}
+	}
+	return r
+}
+
+// truncateText keeps the first keep bytes of s, backing off to a rune
+// boundary, and notes how many bytes were dropped.
+func truncateText(s string, keep int) string {
+	for keep > 0 && keep < len(s) && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	return s[:keep] + fmt.Sprintf("\n\n[truncated: %d bytes did not fit on the page]", len(s)-keep)
+}
+
 func renderBlock(block parser.ContentBlock) string {
 	switch block.Type {
 	case "text":
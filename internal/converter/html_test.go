@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+func textMessage(role, text string) parser.Message {
+	return parser.Message{Role: role, Blocks: []parser.ContentBlock{{Type: "text", Content: text}}}
+}
+
+func TestConvertPagesOversizedMessage(t *testing.T) {
+	const maxBytes = 200 * 1024
+	huge := strings.Repeat("line of <tool> output & more\n", 3*maxBytes/28)
+	cfg := Config{
+		Title:    "Oversized",
+		Username: "dev",
+		PageURL:  func(n int) string { return fmt.Sprintf("https://example.test/page-%d.html", n) },
+	}
+
+	tests := []struct {
+		name     string
+		messages []parser.Message
+	}{
+		{
+			name: "tool result",
+			messages: []parser.Message{
+				textMessage("user", "run it"),
+				{Role: "assistant", Blocks: []parser.ContentBlock{{Type: "tool_result", ToolName: "Bash", Content: huge}}},
+				textMessage("assistant", "done"),
+			},
+		},
+		{
+			name:     "text",
+			messages: []parser.Message{textMessage("user", huge)},
+		},
+		{
+			name: "among many messages",
+			messages: append(
+				slices.Repeat([]parser.Message{textMessage("user", strings.Repeat("x", 10*1024))}, 40),
+				textMessage("assistant", huge),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := ConvertPages(tt.messages, cfg, maxBytes)
+			truncated := false
+			for i, page := range pages {
+				if len(page) > maxBytes {
+					t.Errorf("page %d is %d bytes, want at most %d", i+1, len(page), maxBytes)
+				}
+				if strings.Contains(page, "[truncated: ") {
+					truncated = true
+				}
+			}
+			if !truncated {
+				t.Error("no page notes the truncated message")
+			}
+		})
+	}
+}
+
+func TestConvertChainOversizedMessage(t *testing.T) {
+	const maxBytes = 200 * 1024
+	huge := strings.Repeat("é", maxBytes)
+	sections := []ChainSection{
+		{SessionID: "a", Title: "First", Messages: []parser.Message{textMessage("user", "hello")}},
+		{SessionID: "b", Title: "Second", Messages: []parser.Message{
+			{Role: "assistant", Blocks: []parser.ContentBlock{{Type: "tool_result", ToolName: "Read", Content: huge}}},
+		}},
+	}
+	cfg := Config{Title: "Chain", PageURL: func(n int) string { return fmt.Sprintf("page-%d.html", n) }}
+
+	pages := ConvertChain(sections, cfg, maxBytes)
+	for i, page := range pages {
+		if len(page) > maxBytes {
+			t.Errorf("page %d is %d bytes, want at most %d", i+1, len(page), maxBytes)
+		}
+		if !utf8.ValidString(page) {
+			t.Errorf("page %d cuts a rune in half", i+1)
+		}
+	}
+	if !strings.Contains(strings.Join(pages, ""), "[truncated: ") {
+		t.Error("no page notes the truncated message")
+	}
+}
+
+func TestConvertPagesWithoutLimitKeepsEverything(t *testing.T) {
+	huge := strings.Repeat("a", 300*1024)
+	pages := ConvertPages([]parser.Message{textMessage("user", huge)}, Config{Title: "All"}, 0)
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages, want 1", len(pages))
+	}
+	if strings.Contains(pages[0], "[truncated: ") || !strings.Contains(pages[0], huge) {
+		t.Error("message was cut short without a page limit")
+	}
+}
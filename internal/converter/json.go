@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+type jsonThread struct {
+	Title    string        `json:"title"`
+	Author   string        `json:"author,omitempty"`
+	Project  string        `json:"project,omitempty"`
+	Note     string        `json:"note,omitempty"`
+	Messages []jsonMessage `json:"messages"`
+}
+
+type jsonMessage struct {
+	ID        string      `json:"id,omitempty"`
+	Role      string      `json:"role"`
+	Timestamp *time.Time  `json:"timestamp,omitempty"`
+	Blocks    []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	Type      string `json:"type"`
+	Content   string `json:"content,omitempty"`
+	ToolName  string `json:"tool_name,omitempty"`
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	ToolInput string `json:"tool_input,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+// JSON returns the parsed thread as indented JSON, one entry per message
+// with its content blocks, for tools that want the data rather than a page.
+func JSON(messages []parser.Message, cfg Config) string {
+	thread := jsonThread{
+		Title:    cfg.Title,
+		Author:   cfg.Username,
+		Project:  cfg.ProjectPath,
+		Note:     cfg.Note,
+		Messages: make([]jsonMessage, 0, len(messages)),
+	}
+	for _, msg := range messages {
+		m := jsonMessage{ID: msg.ID, Role: msg.Role, Blocks: make([]jsonBlock, 0, len(msg.Blocks))}
+		if !msg.Timestamp.IsZero() {
+			ts := msg.Timestamp
+			m.Timestamp = &ts
+		}
+		for _, b := range msg.Blocks {
+			m.Blocks = append(m.Blocks, jsonBlock{
+				Type:      b.Type,
+				Content:   b.Content,
+				ToolName:  b.ToolName,
+				ToolUseID: b.ToolUseID,
+				ToolInput: b.ToolInput,
+				IsError:   b.IsError,
+			})
+		}
+		thread.Messages = append(thread.Messages, m)
+	}
+
+	// Only strings, bools and times are marshalled, which cannot fail.
+	data, _ := json.MarshalIndent(thread, "", "  ")
+	return string(data) + "\n"
+}
@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+// Markdown renders the thread as GitHub-flavored Markdown. Thinking and tool
+// output are folded into <details> blocks, as in the HTML export.
+func Markdown(messages []parser.Message, cfg Config) string {
+	messages = mergeToolResults(messages)
+	messages = mergeBashMessages(messages)
+
+	var md strings.Builder
+	md.WriteString("# " + cfg.Title + "\n\n")
+	if cfg.Username != "" {
+		md.WriteString("_" + cfg.Username + "_")
+		if cfg.ProjectPath != "" {
+			md.WriteString(" · `" + cfg.ProjectPath + "`")
+		}
+		md.WriteString("\n\n")
+	}
+	if cfg.PrevSessionURL != "" || cfg.NextSessionURL != "" {
+		var links []string
+		if cfg.PrevSessionURL != "" {
+			links = append(links, "[← Previous Session]("+cfg.PrevSessionURL+")")
+		}
+		if cfg.NextSessionURL != "" {
+			links = append(links, "[Next Session →]("+cfg.NextSessionURL+")")
+		}
+		md.WriteString(strings.Join(links, " · ") + "\n\n")
+	}
+	if note := strings.TrimSpace(cfg.Note); note != "" {
+		md.WriteString("> **Author's note**\n>\n> " + strings.ReplaceAll(note, "\n", "\n> ") + "\n\n")
+	}
+	if len(cfg.Branches) > 1 {
+		var links []string
+		for _, l := range cfg.Branches {
+			links = append(links, "["+l.Title+"]("+l.URL+")")
+		}
+		md.WriteString("Continued in: " + strings.Join(links, " · ") + "\n\n")
+	}
+
+	for _, msg := range messages {
+		var content strings.Builder
+		for _, block := range msg.Blocks {
+			content.WriteString(markdownBlock(block))
+		}
+		if strings.TrimSpace(content.String()) == "" {
+			continue
+		}
+
+		role := "Claude"
+		if msg.Role == "user" {
+			role = cfg.Username
+			if role == "" {
+				role = "User"
+			}
+		}
+		md.WriteString("---\n\n### " + role + "\n\n")
+		md.WriteString(content.String())
+	}
+	return md.String()
+}
+
+func markdownBlock(block parser.ContentBlock) string {
+	switch block.Type {
+	case "text":
+		content := strings.TrimSpace(block.Content)
+		if content == "" || content == "[Request interrupted by user for tool use]" {
+			return ""
+		}
+		return content + "\n\n"
+
+	case "thinking":
+		return markdownDetails("Thinking", "", block.Content)
+
+	case "tool_use":
+		return markdownDetails("Tool: "+block.ToolName, "json", block.ToolInput)
+
+	case "tool_result":
+		summary := "Result"
+		if block.ToolName != "" {
+			summary = block.ToolName + " result"
+		}
+		if block.IsError {
+			summary += " (error)"
+		}
+		return markdownDetails(summary, "", block.Content)
+
+	case "bash_combined":
+		out := fence("bash", "$ "+block.Content)
+		if output := strings.TrimSpace(block.ToolInput + "\n" + block.ToolName); output != "" {
+			out += fence("", output)
+		}
+		return out
+
+	case "command":
+		if block.ToolName == "" {
+			return ""
+		}
+		return "`" + block.ToolName + "`\n\n"
+
+	case "local_command_output":
+		content := strings.TrimSpace(block.Content)
+		if content == "" || content == "(no content)" {
+			return ""
+		}
+		return fence("", content)
+	}
+	return ""
+}
+
+func markdownDetails(summary, lang, content string) string {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	return fmt.Sprintf("<details>\n<summary>%s</summary>\n\n%s</details>\n\n", summary, fence(lang, content))
+}
+
+// fence wraps content in a code fence longer than any backtick run inside it.
+func fence(lang, content string) string {
+	ticks := "```"
+	for strings.Contains(content, ticks) {
+		ticks += "`"
+	}
+	return ticks + lang + "\n" + strings.TrimRight(content, "\n") + "\n" + ticks + "\n\n"
+}
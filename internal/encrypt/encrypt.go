@@ -0,0 +1,80 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/priyanshujain/claude-coding/internal/template"
+)
+
+const (
+	Iterations = 600000
+	saltSize   = 16
+	keySize    = 32
+)
+
+var ErrEmptyPassphrase = errors.New("passphrase must not be empty")
+
+type payload struct {
+	Version    int    `json:"v"`
+	KDF        string `json:"kdf"`
+	Hash       string `json:"hash"`
+	Iterations int    `json:"iterations"`
+	Salt       string `json:"salt"`
+	IV         string `json:"iv"`
+	Data       string `json:"data"`
+}
+
+func HTML(content, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, Iterations, keySize)
+	if err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(content), nil)
+
+	data, err := json.Marshal(payload{
+		Version:    1,
+		KDF:        "PBKDF2",
+		Hash:       "SHA-256",
+		Iterations: Iterations,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		IV:         base64.StdEncoding.EncodeToString(iv),
+		Data:       base64.StdEncoding.EncodeToString(sealed),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(template.EncryptedTemplate, "PAYLOAD_PLACEHOLDER", string(data)), nil
+}
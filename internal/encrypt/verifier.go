@@ -0,0 +1,54 @@
+package encrypt
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NewVerifier returns a value that tells whether a later passphrase is the
+// same as this one without storing the passphrase. It is derived like the
+// encryption key, from its own salt.
+func NewVerifier(passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", ErrEmptyPassphrase
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	check, err := pbkdf2.Key(sha256.New, passphrase, salt, Iterations, keySize)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", Iterations,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(check)), nil
+}
+
+// Verify reports whether passphrase matches a verifier from NewVerifier.
+func Verify(verifier, passphrase string) bool {
+	parts := strings.Split(verifier, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iterations, err := strconv.Atoi(parts[1])
+	if err != nil || iterations <= 0 {
+		return false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	check, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, len(want))
+	return err == nil && hmac.Equal(check, want)
+}
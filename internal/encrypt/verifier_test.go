@@ -0,0 +1,37 @@
+package encrypt
+
+import "testing"
+
+func TestVerifier(t *testing.T) {
+	verifier, err := NewVerifier("correct horse")
+	if err != nil {
+		t.Fatalf("NewVerifier: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		verifier   string
+		passphrase string
+		want       bool
+	}{
+		{"same passphrase", verifier, "correct horse", true},
+		{"other passphrase", verifier, "battery staple", false},
+		{"empty passphrase", verifier, "", false},
+		{"no verifier", "", "correct horse", false},
+		{"malformed", "pbkdf2-sha256$x$y", "correct horse", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.verifier, tt.passphrase); got != tt.want {
+				t.Errorf("Verify = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if again, _ := NewVerifier("correct horse"); again == verifier {
+		t.Error("two verifiers of the same passphrase are equal; the salt is not random")
+	}
+	if _, err := NewVerifier(""); err != ErrEmptyPassphrase {
+		t.Errorf("NewVerifier(\"\") error = %v, want ErrEmptyPassphrase", err)
+	}
+}
@@ -1,85 +1,320 @@
 package gist
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/preview"
+)
+
+const (
+	DefaultHost    = "github.com"
+	DefaultBaseURL = "https://api.github.com"
+)
+
+type Visibility string
+
+const (
+	Public Visibility = "public"
+	Secret Visibility = "secret"
 )
 
-func IsGHAvailable() bool {
-	_, err := exec.LookPath("gh")
-	return err == nil
+func ParseVisibility(value string) (Visibility, error) {
+	switch v := Visibility(strings.ToLower(strings.TrimSpace(value))); v {
+	case Public, Secret:
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid gist visibility %q (want public or secret)", value)
 }
 
-func IsGHAuthenticated() bool {
-	cmd := exec.Command("gh", "auth", "status")
-	return cmd.Run() == nil
+type Client struct {
+	Host            string
+	BaseURL         string
+	Token           string
+	PreviewTemplate string
+	HTTPClient      *http.Client
 }
 
-func buildDescription(gistID string) string {
-	return fmt.Sprintf("This is a Claude Code thread exported as HTML. You can preview it at https://gistpreview.github.io/?%s. Please do not delete it if you have shared the preview link with others, as doing so may break the link.", gistID)
+type HostOptions struct {
+	Host            string
+	APIURL          string
+	PreviewTemplate string
 }
 
-func PreviewURL(gistID string) string {
-	return fmt.Sprintf("https://gistpreview.github.io/?%s", gistID)
+type APIError struct {
+	Method           string
+	Path             string
+	StatusCode       int
+	Message          string
+	DocumentationURL string
+	RateLimited      bool
+	RetryAfter       time.Duration
 }
 
-func Create(filename, htmlContent string) (gistID string, previewURL string, err error) {
-	if !IsGHAvailable() {
-		return "", "", fmt.Errorf("gh CLI is not installed")
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
 	}
+	return fmt.Sprintf("github: %s %s: %d %s", e.Method, e.Path, e.StatusCode, msg)
+}
+
+// File is one file of a gist.
+type File struct {
+	Name    string
+	Content string
+}
+
+type gistFile struct {
+	Content string `json:"content"`
+}
+
+type gistRequest struct {
+	Description string               `json:"description,omitempty"`
+	Public      *bool                `json:"public,omitempty"`
+	Files       map[string]*gistFile `json:"files,omitempty"`
+}
+
+type gistResponse struct {
+	ID      string `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+type errorResponse struct {
+	Message          string `json:"message"`
+	DocumentationURL string `json:"documentation_url"`
+}
+
+func NewClient(opts HostOptions) (*Client, error) {
+	host := NormalizeHost(opts.Host)
 
-	tmpDir, err := os.MkdirTemp("", "claude-gist-*")
+	token, err := Token(host)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create temp dir: %w", err)
+		return nil, err
 	}
-	defer os.RemoveAll(tmpDir)
 
-	tmpFilePath := filepath.Join(tmpDir, filename)
-	if err := os.WriteFile(tmpFilePath, []byte(htmlContent), 0644); err != nil {
-		return "", "", fmt.Errorf("failed to write temp file: %w", err)
+	return &Client{
+		Host:            host,
+		BaseURL:         APIURL(host, opts.APIURL),
+		Token:           token,
+		PreviewTemplate: PreviewTemplate(host, opts.PreviewTemplate),
+		HTTPClient:      &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	host = strings.TrimSuffix(host, "/")
+	if host == "" || host == "api.github.com" {
+		return DefaultHost
 	}
+	return strings.ToLower(host)
+}
 
-	cmd := exec.Command("gh", "gist", "create", "--public", tmpFilePath)
-	output, err := cmd.Output()
-	if err != nil {
+func IsEnterprise(host string) bool {
+	return NormalizeHost(host) != DefaultHost
+}
+
+func APIURL(host, override string) string {
+	if override != "" {
+		return override
+	}
+	if !IsEnterprise(host) {
+		if env := os.Getenv("GITHUB_API_URL"); env != "" {
+			return env
+		}
+		return DefaultBaseURL
+	}
+	return "https://" + NormalizeHost(host) + "/api/v3"
+}
+
+func PreviewTemplate(host, override string) string {
+	if override != "" {
+		return override
+	}
+	provider := preview.GistPreview
+	if IsEnterprise(host) {
+		provider = preview.GistPage
+	}
+	template, _ := preview.Template(provider, "")
+	return template
+}
+
+func (c *Client) PreviewURL(gistID, filename string) string {
+	return preview.Format(c.PreviewTemplate, preview.Target{Host: c.Host, ID: gistID, File: filename})
+}
+
+func (c *Client) buildDescription(gistID, filename string) string {
+	return fmt.Sprintf("This is a Claude Code thread exported as HTML. You can preview it at %s. Please do not delete it if you have shared the preview link with others, as doing so may break the link.", c.PreviewURL(gistID, filename))
+}
+
+// initialDescription is set when a gist is created, before its ID and so
+// its preview URL are known.
+const initialDescription = "This is a Claude Code thread exported as HTML. Please do not delete it if you have shared its preview link with others, as doing so may break the link."
+
+func (c *Client) CheckAuth() error {
+	return c.do(http.MethodGet, "/user", nil, nil)
+}
+
+func gistFiles(files []File, removed []string) map[string]*gistFile {
+	result := make(map[string]*gistFile, len(files)+len(removed))
+	for _, name := range removed {
+		result[name] = nil
+	}
+	for _, f := range files {
+		result[f.Name] = &gistFile{Content: f.Content}
+	}
+	return result
+}
+
+// Create uploads files as a new gist. The first file is the one the preview
+// URL points to.
+func (c *Client) Create(files []File, visibility Visibility) (gistID string, previewURL string, err error) {
+	if len(files) == 0 {
+		return "", "", fmt.Errorf("failed to create gist: no files")
+	}
+	filename := files[0].Name
+	public := visibility == Public
+	req := gistRequest{
+		Description: initialDescription,
+		Public:      &public,
+		Files:       gistFiles(files, nil),
+	}
+
+	var resp gistResponse
+	if err := c.do(http.MethodPost, "/gists", req, &resp); err != nil {
 		return "", "", fmt.Errorf("failed to create gist: %w", err)
 	}
+	if resp.ID == "" {
+		return "", "", fmt.Errorf("failed to create gist: response did not include a gist id")
+	}
 
-	gistURL := strings.TrimSpace(string(output))
-	gistID = filepath.Base(gistURL)
-	previewURL = PreviewURL(gistID)
+	// The gist exists at this point, so failing to add the preview URL to
+	// its description must not fail the upload.
+	if err := c.do(http.MethodPatch, "/gists/"+resp.ID, gistRequest{Description: c.buildDescription(resp.ID, filename)}, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: gist %s was created but its description could not be updated: %v\n", resp.ID, err)
+	}
 
-	desc := buildDescription(gistID)
-	descCmd := exec.Command("gh", "gist", "edit", gistID, "--desc", desc)
-	descCmd.Run()
+	return resp.ID, c.PreviewURL(resp.ID, filename), nil
+}
+
+// Update replaces the content of files in the gist and deletes the files
+// named in removed.
+func (c *Client) Update(gistID string, files []File, removed []string) (previewURL string, err error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("failed to update gist: no files")
+	}
+	filename := files[0].Name
+	req := gistRequest{
+		Description: c.buildDescription(gistID, filename),
+		Files:       gistFiles(files, removed),
+	}
+
+	if err := c.do(http.MethodPatch, "/gists/"+gistID, req, nil); err != nil {
+		return "", fmt.Errorf("failed to update gist: %w", err)
+	}
 
-	return gistID, previewURL, nil
+	return c.PreviewURL(gistID, filename), nil
 }
 
-func Update(gistID, filename, htmlContent string) (previewURL string, err error) {
-	if !IsGHAvailable() {
-		return "", fmt.Errorf("gh CLI is not installed")
+func (c *Client) Delete(gistID string) error {
+	if err := c.do(http.MethodDelete, "/gists/"+gistID, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete gist: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) do(method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
 	}
 
-	tmpDir, err := os.MkdirTemp("", "claude-gist-*")
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.BaseURL, "/")+path, reader)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp dir: %w", err)
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "claude-coding")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer os.RemoveAll(tmpDir)
 
-	tmpFilePath := filepath.Join(tmpDir, filename)
-	if err := os.WriteFile(tmpFilePath, []byte(htmlContent), 0644); err != nil {
-		return "", fmt.Errorf("failed to write temp file: %w", err)
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
 	}
 
-	desc := buildDescription(gistID)
-	cmd := exec.Command("gh", "gist", "edit", gistID, "--add", tmpFilePath, "--desc", desc)
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("failed to update gist: %w", err)
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{Method: method, Path: path, StatusCode: resp.StatusCode}
+		var errResp errorResponse
+		if json.Unmarshal(data, &errResp) == nil {
+			apiErr.Message = errResp.Message
+			apiErr.DocumentationURL = errResp.DocumentationURL
+		}
+		apiErr.RetryAfter = RetryAfter(resp.Header, time.Now())
+		apiErr.RateLimited = resp.StatusCode == http.StatusTooManyRequests ||
+			resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			(resp.StatusCode == http.StatusForbidden && resp.Header.Get("Retry-After") != "") ||
+			strings.Contains(strings.ToLower(apiErr.Message), "rate limit")
+		return apiErr
 	}
 
-	return PreviewURL(gistID), nil
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("github: %s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// RetryAfter returns how long the server asked clients to wait, from a
+// Retry-After header or, once the quota is spent, GitHub's rate-limit reset
+// time. It returns 0 when the response does not say.
+func RetryAfter(h http.Header, now time.Time) time.Duration {
+	if value := h.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if at, err := http.ParseTime(value); err == nil && at.After(now) {
+			return at.Sub(now)
+		}
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			if at := time.Unix(reset, 0); at.After(now) {
+				return at.Sub(now)
+			}
+		}
+	}
+	return 0
 }
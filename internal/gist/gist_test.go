@@ -0,0 +1,292 @@
+package gist
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordedRequest struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newTestClient returns a client talking to a server that records every
+// request and answers with handler.
+func newTestClient(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*Client, *[]recordedRequest) {
+	t.Helper()
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := recordedRequest{Method: r.Method, Path: r.URL.Path, Header: r.Header.Clone()}
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &rec.Body); err != nil {
+				t.Errorf("%s %s: invalid JSON body: %v", r.Method, r.URL.Path, err)
+			}
+		}
+		requests = append(requests, rec)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{
+		Host:            DefaultHost,
+		BaseURL:         server.URL,
+		Token:           "test-token",
+		PreviewTemplate: PreviewTemplate(DefaultHost, ""),
+		HTTPClient:      server.Client(),
+	}, &requests
+}
+
+func TestCreate(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"id":"abc123","html_url":"https://gist.github.com/abc123"}`)
+			return
+		}
+		io.WriteString(w, `{"id":"abc123"}`)
+	})
+
+	id, previewURL, err := client.Create([]File{{Name: "thread.html", Content: "<html>"}, {Name: "thread.md", Content: "# md"}}, Secret)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if id != "abc123" {
+		t.Errorf("id = %q, want abc123", id)
+	}
+	if want := "https://gistpreview.github.io/?abc123/thread.html"; previewURL != want {
+		t.Errorf("preview URL = %q, want %q", previewURL, want)
+	}
+
+	if len(*requests) != 2 {
+		t.Fatalf("got %d requests, want POST and PATCH", len(*requests))
+	}
+	post, patch := (*requests)[0], (*requests)[1]
+	if post.Method != http.MethodPost || post.Path != "/gists" {
+		t.Errorf("first request = %s %s, want POST /gists", post.Method, post.Path)
+	}
+	if post.Body["public"] != false {
+		t.Errorf("public = %v, want false for a secret gist", post.Body["public"])
+	}
+	if post.Body["description"] == "" || post.Body["description"] == nil {
+		t.Error("POST has no description")
+	}
+	files, _ := post.Body["files"].(map[string]any)
+	if file, _ := files["thread.md"].(map[string]any); file["content"] != "# md" {
+		t.Errorf("files = %v, want thread.md with its content", files)
+	}
+
+	if patch.Method != http.MethodPatch || patch.Path != "/gists/abc123" {
+		t.Errorf("second request = %s %s, want PATCH /gists/abc123", patch.Method, patch.Path)
+	}
+	if desc, _ := patch.Body["description"].(string); !strings.Contains(desc, previewURL) {
+		t.Errorf("description %q does not contain the preview URL", desc)
+	}
+}
+
+func TestCreatePublic(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"abc123"}`)
+	})
+	if _, _, err := client.Create([]File{{Name: "thread.html", Content: "x"}}, Public); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got := (*requests)[0].Body["public"]; got != true {
+		t.Errorf("public = %v, want true", got)
+	}
+}
+
+func TestCreateKeepsGistWhenDescriptionFails(t *testing.T) {
+	client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		io.WriteString(w, `{"id":"abc123"}`)
+	})
+	id, _, err := client.Create([]File{{Name: "thread.html", Content: "x"}}, Secret)
+	if err != nil || id != "abc123" {
+		t.Errorf("Create = %q, %v; want the created gist and no error", id, err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":"abc123"}`)
+	})
+
+	previewURL, err := client.Update("abc123", []File{{Name: "thread.html", Content: "new"}}, []string{"thread.p2.html"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if want := "https://gistpreview.github.io/?abc123/thread.html"; previewURL != want {
+		t.Errorf("preview URL = %q, want %q", previewURL, want)
+	}
+
+	req := (*requests)[0]
+	if req.Method != http.MethodPatch || req.Path != "/gists/abc123" {
+		t.Errorf("request = %s %s, want PATCH /gists/abc123", req.Method, req.Path)
+	}
+	files, _ := req.Body["files"].(map[string]any)
+	if removed, ok := files["thread.p2.html"]; !ok || removed != nil {
+		t.Errorf("removed file = %v (present %v), want null to delete it", removed, ok)
+	}
+	if file, _ := files["thread.html"].(map[string]any); file["content"] != "new" {
+		t.Errorf("thread.html = %v, want the new content", files["thread.html"])
+	}
+	if _, ok := req.Body["public"]; ok {
+		t.Error("update must not change the visibility")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	if err := client.Delete("abc123"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if req := (*requests)[0]; req.Method != http.MethodDelete || req.Path != "/gists/abc123" {
+		t.Errorf("request = %s %s, want DELETE /gists/abc123", req.Method, req.Path)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	client, requests := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"login":"octocat"}`)
+	})
+	if err := client.CheckAuth(); err != nil {
+		t.Fatalf("CheckAuth: %v", err)
+	}
+
+	req := (*requests)[0]
+	if req.Path != "/user" {
+		t.Errorf("path = %q, want /user", req.Path)
+	}
+	for header, want := range map[string]string{
+		"Authorization":        "Bearer test-token",
+		"Accept":               "application/vnd.github+json",
+		"X-Github-Api-Version": "2022-11-28",
+		"User-Agent":           "claude-coding",
+	} {
+		if got := req.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	client.Token = ""
+	client.CheckAuth()
+	if got := (*requests)[1].Header.Get("Authorization"); got != "" {
+		t.Errorf("Authorization = %q without a token, want none", got)
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	reset := time.Now().Add(90 * time.Second).Unix()
+
+	tests := []struct {
+		name        string
+		status      int
+		header      map[string]string
+		body        string
+		rateLimited bool
+		retryAfter  bool
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"message":"Not Found","documentation_url":"https://docs.github.com"}`,
+		},
+		{
+			name:   "bad credentials",
+			status: http.StatusUnauthorized,
+			body:   `{"message":"Bad credentials"}`,
+		},
+		{
+			name:        "primary rate limit",
+			status:      http.StatusForbidden,
+			header:      map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": strconv.FormatInt(reset, 10)},
+			body:        `{"message":"API rate limit exceeded"}`,
+			rateLimited: true,
+			retryAfter:  true,
+		},
+		{
+			name:        "secondary rate limit",
+			status:      http.StatusForbidden,
+			header:      map[string]string{"Retry-After": "30"},
+			body:        `{"message":"You have exceeded a secondary rate limit"}`,
+			rateLimited: true,
+			retryAfter:  true,
+		},
+		{
+			name:        "too many requests",
+			status:      http.StatusTooManyRequests,
+			rateLimited: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			})
+
+			err := client.Delete("abc123")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an *APIError", err)
+			}
+			if apiErr.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", apiErr.StatusCode, tt.status)
+			}
+			if apiErr.RateLimited != tt.rateLimited {
+				t.Errorf("RateLimited = %v, want %v", apiErr.RateLimited, tt.rateLimited)
+			}
+			if (apiErr.RetryAfter > 0) != tt.retryAfter {
+				t.Errorf("RetryAfter = %v, want set: %v", apiErr.RetryAfter, tt.retryAfter)
+			}
+			if tt.body != "" {
+				var body errorResponse
+				json.Unmarshal([]byte(tt.body), &body)
+				if apiErr.Message != body.Message {
+					t.Errorf("message = %q, want %q", apiErr.Message, body.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"seconds", http.Header{"Retry-After": {"30"}}, 30 * time.Second},
+		{"date", http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute},
+		{"reset", http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {strconv.FormatInt(now.Add(2*time.Minute).Unix(), 10)}}, 2 * time.Minute},
+		{"quota left", http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {strconv.FormatInt(now.Add(time.Hour).Unix(), 10)}}, 0},
+		{"none", http.Header{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RetryAfter(tt.header, now); got != tt.want {
+				t.Errorf("RetryAfter = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
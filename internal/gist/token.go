@@ -0,0 +1,102 @@
+package gist
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoToken = errors.New("no GitHub token found: set GH_TOKEN or GITHUB_TOKEN, or run gh auth login")
+
+var ErrNoEnterpriseToken = errors.New("no GitHub Enterprise token found: set GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN, or run gh auth login --hostname <host>")
+
+func Token(host string) (string, error) {
+	host = NormalizeHost(host)
+
+	envNames := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if IsEnterprise(host) {
+		envNames = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, name := range envNames {
+		if token := strings.TrimSpace(os.Getenv(name)); token != "" {
+			return token, nil
+		}
+	}
+
+	if token := ghConfigToken(host); token != "" {
+		return token, nil
+	}
+
+	if token := ghCLIToken(host); token != "" {
+		return token, nil
+	}
+
+	if IsEnterprise(host) {
+		return "", ErrNoEnterpriseToken
+	}
+	return "", ErrNoToken
+}
+
+func ghConfigDir() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "gh")
+}
+
+func ghConfigToken(host string) string {
+	dir := ghConfigDir()
+	if dir == "" {
+		return ""
+	}
+
+	file, err := os.Open(filepath.Join(dir, "hosts.yml"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inHost := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inHost = strings.TrimSuffix(trimmed, ":") == host
+			continue
+		}
+
+		if !inHost {
+			continue
+		}
+		if value, ok := strings.CutPrefix(trimmed, "oauth_token:"); ok {
+			return strings.Trim(strings.TrimSpace(value), `"'`)
+		}
+	}
+	return ""
+}
+
+func ghCLIToken(host string) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+	output, err := exec.Command("gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
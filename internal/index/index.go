@@ -0,0 +1,197 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/parser"
+)
+
+// Update refreshes the index entries of sessions of a project from their
+// session files and the project metadata. A session file is only parsed
+// again when it changed since it was last indexed, and never while the
+// index is locked.
+func Update(projectPath string, sessionIDs ...string) error {
+	projectDir, err := metadata.ProjectDir(projectPath)
+	if err != nil {
+		return err
+	}
+	m, err := metadata.LoadMetadataDir(projectDir)
+	if err != nil && !errors.Is(err, metadata.ErrNewerVersion) {
+		return err
+	}
+	cached, _ := metadata.LoadIndex()
+
+	entries := make([]metadata.IndexEntry, len(sessionIDs))
+	for i, id := range sessionIDs {
+		entries[i] = entry(projectPath, projectDir, id, m, cached.Sessions[id])
+	}
+	return metadata.WithIndex(func(idx *metadata.Index) error {
+		for _, e := range entries {
+			idx.Sessions[e.SessionID] = e
+		}
+		// Sessions indexed before the project path was known, such as
+		// those without a cwd in their log, pick it up here.
+		for id, e := range idx.Sessions {
+			if e.ProjectPath == "" && e.ProjectDir == filepath.Base(projectDir) {
+				e.ProjectPath = projectPath
+				idx.Sessions[id] = e
+			}
+		}
+		return nil
+	})
+}
+
+// Remove drops sessions from the index.
+func Remove(sessionIDs ...string) error {
+	return metadata.WithIndex(func(idx *metadata.Index) error {
+		for _, id := range sessionIDs {
+			delete(idx.Sessions, id)
+		}
+		return nil
+	})
+}
+
+// Rebuild indexes every session file under ~/.claude/projects, along with
+// sessions whose file is gone but which are still shared, and drops
+// everything else. It returns the number of sessions and projects indexed.
+func Rebuild() (sessions, projects int, err error) {
+	projectsDir, err := metadata.ProjectsDir()
+	if err != nil {
+		return 0, 0, err
+	}
+	dirs, err := os.ReadDir(projectsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, 0, err
+	}
+	cached, _ := metadata.LoadIndex()
+	started := time.Now()
+
+	var entries []metadata.IndexEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		projectDir := filepath.Join(projectsDir, dir.Name())
+		ids := sessionIDs(projectDir)
+		m, _ := metadata.LoadMetadataDir(projectDir)
+		for id := range m.Sessions {
+			if _, ok := ids[id]; !ok && len(m.GetShares(id)) > 0 {
+				ids[id] = true
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+
+		projectPath := ""
+		for id := range ids {
+			if projectPath = cached.Sessions[id].ProjectPath; projectPath != "" {
+				break
+			}
+		}
+		for id := range ids {
+			e := entry(projectPath, projectDir, id, m, cached.Sessions[id])
+			projectPath = e.ProjectPath
+			entries = append(entries, e)
+		}
+		projects++
+	}
+
+	err = metadata.WithIndex(func(idx *metadata.Index) error {
+		rebuilt := make(map[string]metadata.IndexEntry, len(entries))
+		// Sessions a hook indexed while the rebuild ran are kept.
+		for id, e := range idx.Sessions {
+			if e.UpdatedAt.After(started) {
+				rebuilt[id] = e
+			}
+		}
+		for _, e := range entries {
+			if _, ok := rebuilt[e.SessionID]; !ok {
+				rebuilt[e.SessionID] = e
+			}
+		}
+		idx.Sessions = rebuilt
+		sessions = len(rebuilt)
+		return nil
+	})
+	return sessions, projects, err
+}
+
+// sessionIDs returns the sessions that have a file in a project directory.
+func sessionIDs(projectDir string) map[string]bool {
+	ids := make(map[string]bool)
+	files, _ := os.ReadDir(projectDir)
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".jsonl") || strings.HasPrefix(name, "agent-") {
+			continue
+		}
+		ids[strings.TrimSuffix(name, ".jsonl")] = true
+	}
+	return ids
+}
+
+// entry brings the cached entry of a session up to date.
+func entry(projectPath, projectDir, sessionID string, m *metadata.Metadata, e metadata.IndexEntry) metadata.IndexEntry {
+	e.SessionID = sessionID
+	e.ProjectDir = filepath.Base(projectDir)
+	if projectPath != "" {
+		e.ProjectPath = projectPath
+	}
+
+	sessionFile := filepath.Join(projectDir, sessionID+".jsonl")
+	info, err := os.Stat(sessionFile)
+	// A session that just started has no file yet; only one that was
+	// counted or shared before has lost it.
+	e.Missing = err != nil && (!e.ModTime.IsZero() || len(m.GetShares(sessionID)) > 0)
+	if err == nil && (info.Size() != e.Size || !info.ModTime().Equal(e.ModTime)) {
+		if messages, err := parser.ParseFile(sessionFile); err == nil {
+			count(&e, messages)
+			e.Summary = parser.ParseSummary(sessionFile)
+			if e.Summary == "" {
+				e.Summary = parser.ExtractTitle(messages)
+			}
+			e.Size, e.ModTime = info.Size(), info.ModTime()
+		}
+	}
+	if e.ProjectPath == "" && !e.Missing {
+		e.ProjectPath = parser.ParseCwd(sessionFile)
+	}
+
+	e.Title = m.GetTitle(sessionID)
+	e.Note = m.GetNote(sessionID)
+	e.Tags = m.GetTags(sessionID)
+	e.Shares = m.GetShares(sessionID)
+	e.UpdatedAt = time.Now()
+	return e
+}
+
+func count(e *metadata.IndexEntry, messages []parser.Message) {
+	e.Start, e.End = time.Time{}, time.Time{}
+	e.Messages, e.Prompts, e.ToolCalls = len(messages), 0, 0
+	for _, msg := range messages {
+		if !msg.Timestamp.IsZero() {
+			if e.Start.IsZero() {
+				e.Start = msg.Timestamp
+			}
+			e.End = msg.Timestamp
+		}
+		prompt := false
+		for _, block := range msg.Blocks {
+			switch {
+			case block.Type == "tool_use":
+				e.ToolCalls++
+			case block.Type == "text" && msg.Role == "user":
+				prompt = true
+			}
+		}
+		if prompt {
+			e.Prompts++
+		}
+	}
+}
@@ -15,6 +15,7 @@ type rawMessage struct {
 	Message   rawContent `json:"message"`
 	IsMeta    bool       `json:"isMeta"`
 	Summary   string     `json:"summary"`
+	Cwd       string     `json:"cwd"`
 }
 
 type rawContent struct {
@@ -80,6 +81,28 @@ func ParseSummary(filePath string) string {
 	return lastSummary
 }
 
+func ParseCwd(filePath string) string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+
+	for scanner.Scan() {
+		var raw rawMessage
+		if err := json.Unmarshal(scanner.Bytes(), &raw); err != nil {
+			continue
+		}
+		if raw.Cwd != "" {
+			return raw.Cwd
+		}
+	}
+	return ""
+}
+
 func ParseFile(filePath string) ([]Message, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
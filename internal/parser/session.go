@@ -85,3 +85,26 @@ func GetSessionFilePath(projectPath, sessionID string) (string, error) {
 	}
 	return sessionFile, nil
 }
+
+// ExtractTitle returns the first prompt of a session, shortened to a line,
+// for sessions without a summary.
+func ExtractTitle(messages []Message) string {
+	for _, msg := range messages {
+		if msg.Role == "user" {
+			for _, block := range msg.Blocks {
+				if block.Type == "text" && block.Content != "" {
+					title := strings.TrimSpace(block.Content)
+					if strings.HasPrefix(title, "Caveat:") || strings.HasPrefix(title, "<") {
+						continue
+					}
+					if len(title) > 80 {
+						title = title[:77] + "..."
+					}
+					lines := strings.Split(title, "\n")
+					return lines[0]
+				}
+			}
+		}
+	}
+	return "Claude Code Thread"
+}
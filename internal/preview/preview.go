@@ -0,0 +1,86 @@
+package preview
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	GistPreview = "gistpreview"
+	HTMLPreview = "htmlpreview"
+	Raw         = "raw"
+	GistPage    = "gist"
+	SelfHosted  = "self-hosted"
+)
+
+var Providers = []string{GistPreview, HTMLPreview, Raw, GistPage, SelfHosted}
+
+var templates = map[string]string{
+	GistPreview: "https://gistpreview.github.io/?{id}/{file}",
+	HTMLPreview: "https://htmlpreview.github.io/?{raw}",
+	Raw:         "{raw}",
+	GistPage:    "{gist}",
+}
+
+type Target struct {
+	Host string
+	ID   string
+	File string
+}
+
+func Template(provider, customURL string) (string, error) {
+	if provider == "" && customURL != "" {
+		provider = SelfHosted
+	}
+	if provider == "" {
+		provider = GistPreview
+	}
+
+	if provider == SelfHosted {
+		if customURL == "" {
+			return "", fmt.Errorf("preview provider %q needs preview.url, e.g. https://viewer.example.com/?id={id}", SelfHosted)
+		}
+		if !strings.Contains(customURL, "{") {
+			return "", fmt.Errorf("preview.url %q has no placeholder ({id}, {file}, {raw}, {gist} or {host})", customURL)
+		}
+		return customURL, nil
+	}
+
+	if t, ok := templates[provider]; ok {
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown preview provider %q (want one of %s)", provider, strings.Join(Providers, ", "))
+}
+
+func Format(template string, t Target) string {
+	if template == "" {
+		template = templates[GistPreview]
+	}
+	return strings.NewReplacer(
+		"{id}", t.ID,
+		"{file}", url.PathEscape(t.File),
+		"{host}", t.Host,
+		"{raw}", RawURL(t),
+		"{gist}", PageURL(t),
+	).Replace(template)
+}
+
+func PageURL(t Target) string {
+	if isDotCom(t.Host) {
+		return "https://gist.github.com/" + t.ID
+	}
+	return "https://" + t.Host + "/gist/" + t.ID
+}
+
+func RawURL(t Target) string {
+	raw := PageURL(t) + "/raw"
+	if t.File != "" {
+		raw += "/" + url.PathEscape(t.File)
+	}
+	return raw
+}
+
+func isDotCom(host string) bool {
+	return host == "" || host == "github.com"
+}
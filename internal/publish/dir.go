@@ -0,0 +1,106 @@
+package publish
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Dir struct {
+	Path    string
+	BaseURL string
+}
+
+func (d *Dir) Publish(files []File) (string, error) {
+	if len(files) == 0 {
+		return "", errNoFiles
+	}
+	for _, f := range files {
+		if err := d.write(f.Name, f.Content); err != nil {
+			return "", err
+		}
+	}
+	return files[0].Name, nil
+}
+
+func (d *Dir) Update(id string, files []File, previous []string) error {
+	if len(files) == 0 {
+		return errNoFiles
+	}
+	if err := d.write(id, files[0].Content); err != nil {
+		return err
+	}
+	for _, f := range files[1:] {
+		if err := d.write(f.Name, f.Content); err != nil {
+			return err
+		}
+	}
+	for _, name := range Stale(previous, files) {
+		if name != id {
+			if err := d.remove(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Dir) Delete(id string, names []string) error {
+	for _, name := range append([]string{id}, names...) {
+		if err := d.remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dir) URL(id, name string) string {
+	if name == "" {
+		name = id
+	}
+	if d.BaseURL != "" {
+		return strings.TrimSuffix(d.BaseURL, "/") + "/" + url.PathEscape(name)
+	}
+	path, err := d.file(name)
+	if err != nil {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func (d *Dir) file(id string) (string, error) {
+	if d.Path == "" {
+		return "", fmt.Errorf("dir %w: set dir.path", ErrNotConfigured)
+	}
+	if id == "" || id != filepath.Base(id) {
+		return "", fmt.Errorf("invalid file name %q", id)
+	}
+	return filepath.Join(d.Path, id), nil
+}
+
+func (d *Dir) write(id, content string) error {
+	path, err := d.file(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.Path, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func (d *Dir) remove(name string) error {
+	path, err := d.file(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package publish
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/gist"
+)
+
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindAuth
+	KindRateLimit
+	KindNotFound
+	KindNetwork
+	KindTooLarge
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindAuth:
+		return "auth"
+	case KindRateLimit:
+		return "rate limit"
+	case KindNotFound:
+		return "not found"
+	case KindNetwork:
+		return "network"
+	case KindTooLarge:
+		return "too large"
+	}
+	return "unknown"
+}
+
+// ExitCode is the process exit status used when publishing fails with an
+// error of this kind.
+func (k Kind) ExitCode() int {
+	switch k {
+	case KindAuth:
+		return 3
+	case KindRateLimit:
+		return 4
+	case KindNotFound:
+		return 5
+	case KindNetwork:
+		return 6
+	case KindTooLarge:
+		return 7
+	}
+	return 1
+}
+
+// Transient reports whether an operation that failed with this kind may
+// succeed if retried.
+func (k Kind) Transient() bool {
+	return k == KindRateLimit || k == KindNetwork
+}
+
+// NotSent reports whether err happened before the request reached the
+// server, such as a failure to resolve or connect to its host.
+func NotSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func Classify(err error) Kind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	if errors.Is(err, gist.ErrNoToken) || errors.Is(err, gist.ErrNoEnterpriseToken) {
+		return KindAuth
+	}
+
+	var apiErr *gist.APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RateLimited {
+			return KindRateLimit
+		}
+		if apiErr.StatusCode == http.StatusUnprocessableEntity && isSizeMessage(apiErr.Message) {
+			return KindTooLarge
+		}
+		return classifyStatus(apiErr.StatusCode)
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		if statusErr.StatusCode == http.StatusServiceUnavailable && strings.Contains(statusErr.Body, "SlowDown") {
+			return KindRateLimit
+		}
+		if statusErr.StatusCode == http.StatusBadRequest && strings.Contains(statusErr.Body, "EntityTooLarge") {
+			return KindTooLarge
+		}
+		return classifyStatus(statusErr.StatusCode)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return KindNetwork
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return KindNotFound
+	}
+	return KindUnknown
+}
+
+func classifyStatus(code int) Kind {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return KindAuth
+	case code == http.StatusTooManyRequests:
+		return KindRateLimit
+	case code == http.StatusNotFound || code == http.StatusGone:
+		return KindNotFound
+	case code == http.StatusRequestEntityTooLarge:
+		return KindTooLarge
+	case code == http.StatusRequestTimeout || code >= 500:
+		return KindNetwork
+	}
+	return KindUnknown
+}
+
+func isSizeMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "too large") || strings.Contains(message, "too big") || strings.Contains(message, "size")
+}
+
+// RetryAfter returns the wait the server asked for, or 0 if it did not.
+func RetryAfter(err error) time.Duration {
+	var apiErr *gist.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package publish
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/priyanshujain/claude-coding/internal/gist"
+)
+
+func TestClassifyGistResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		body   string
+		want   Kind
+	}{
+		{"not found", http.StatusNotFound, nil, `{"message":"Not Found"}`, KindNotFound},
+		{"bad credentials", http.StatusUnauthorized, nil, `{"message":"Bad credentials"}`, KindAuth},
+		{"forbidden", http.StatusForbidden, nil, `{"message":"Resource not accessible"}`, KindAuth},
+		{"rate limit", http.StatusForbidden, map[string]string{"X-RateLimit-Remaining": "0"}, `{"message":"API rate limit exceeded"}`, KindRateLimit},
+		{"secondary rate limit", http.StatusForbidden, map[string]string{"Retry-After": "30"}, `{"message":"You have exceeded a secondary rate limit"}`, KindRateLimit},
+		{"too many requests", http.StatusTooManyRequests, nil, "", KindRateLimit},
+		{"too large", http.StatusUnprocessableEntity, nil, `{"message":"Validation Failed: content is too large"}`, KindTooLarge},
+		{"server error", http.StatusBadGateway, nil, "", KindNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range tt.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			p := &Gist{Client: &gist.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}}
+			err := p.Delete("abc123", nil)
+			if got := Classify(err); got != tt.want {
+				t.Errorf("Classify(%v) = %v, want %v", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package publish
+
+import (
+	"github.com/priyanshujain/claude-coding/internal/gist"
+	"github.com/priyanshujain/claude-coding/internal/preview"
+)
+
+type Gist struct {
+	Client          *gist.Client
+	Host            string
+	Visibility      gist.Visibility
+	PreviewTemplate string
+}
+
+func (g *Gist) Publish(files []File) (string, error) {
+	if g.Client == nil {
+		return "", gist.ErrNoToken
+	}
+	id, _, err := g.Client.Create(gistFiles(files), g.Visibility)
+	return id, err
+}
+
+func (g *Gist) Update(id string, files []File, previous []string) error {
+	if g.Client == nil {
+		return gist.ErrNoToken
+	}
+	_, err := g.Client.Update(id, gistFiles(files), Stale(previous, files))
+	return err
+}
+
+func (g *Gist) Delete(id string, names []string) error {
+	if g.Client == nil {
+		return gist.ErrNoToken
+	}
+	return g.Client.Delete(id)
+}
+
+func (g *Gist) URL(id, name string) string {
+	return preview.Format(g.PreviewTemplate, preview.Target{Host: g.Host, ID: id, File: name})
+}
+
+func gistFiles(files []File) []gist.File {
+	result := make([]gist.File, len(files))
+	for i, f := range files {
+		result[i] = gist.File{Name: f.Name, Content: f.Content}
+	}
+	return result
+}
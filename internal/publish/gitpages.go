@@ -0,0 +1,373 @@
+package publish
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultGitPagesBranch = "gh-pages"
+
+	gitPagesManifest = "threads.json"
+	gitPagesIndex    = "index.html"
+)
+
+// GitPages commits threads to a branch of a local git repository, such as
+// gh-pages, together with an index page listing them. It only uses git
+// plumbing, so neither the working tree nor the index of the repository is
+// touched. Threads link to each other relatively, so the branch can be
+// served from any URL.
+type GitPages struct {
+	Repo    string
+	Branch  string
+	Dir     string
+	BaseURL string
+	Remote  string
+
+	mu sync.Mutex
+}
+
+type gitPagesThread struct {
+	File      string    `json:"file"`
+	Title     string    `json:"title"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type gitEntry struct {
+	mode string
+	sha  string
+}
+
+var titleRe = regexp.MustCompile(`(?is)<title>(.*?)</title>`)
+
+func (g *GitPages) Publish(files []File) (string, error) {
+	if len(files) == 0 {
+		return "", errNoFiles
+	}
+	id := files[0].Name
+	err := g.commit("Publish "+id, func(tree map[string]gitEntry, threads map[string]gitPagesThread) error {
+		if err := g.write(tree, files); err != nil {
+			return err
+		}
+		threads[id] = gitPagesThread{File: id, Title: pageTitle(files[0].Content), UpdatedAt: time.Now().UTC()}
+		return nil
+	})
+	return id, err
+}
+
+func (g *GitPages) Update(id string, files []File, previous []string) error {
+	if len(files) == 0 {
+		return errNoFiles
+	}
+	return g.commit("Update "+id, func(tree map[string]gitEntry, threads map[string]gitPagesThread) error {
+		if _, ok := tree[g.path(id)]; !ok {
+			return fmt.Errorf("git-pages %s: %w", id, os.ErrNotExist)
+		}
+		files := append([]File{{Name: id, Content: files[0].Content}}, files[1:]...)
+		if err := g.write(tree, files); err != nil {
+			return err
+		}
+		for _, name := range Stale(previous, files) {
+			delete(tree, g.path(name))
+		}
+		threads[id] = gitPagesThread{File: id, Title: pageTitle(files[0].Content), UpdatedAt: time.Now().UTC()}
+		return nil
+	})
+}
+
+func (g *GitPages) Delete(id string, names []string) error {
+	return g.commit("Delete "+id, func(tree map[string]gitEntry, threads map[string]gitPagesThread) error {
+		for _, name := range append([]string{id}, names...) {
+			if err := validName(name); err != nil {
+				return err
+			}
+			delete(tree, g.path(name))
+		}
+		delete(threads, id)
+		return nil
+	})
+}
+
+func (g *GitPages) URL(id, name string) string {
+	if name == "" {
+		name = id
+	}
+	if g.BaseURL == "" {
+		return g.Link(id, name)
+	}
+	return strings.TrimSuffix(g.BaseURL, "/") + "/" + url.PathEscape(name)
+}
+
+// Link returns the URL of a file relative to the other threads, which all
+// live in the same directory of the branch.
+func (g *GitPages) Link(id, name string) string {
+	if name == "" {
+		name = id
+	}
+	return url.PathEscape(name)
+}
+
+func (g *GitPages) branch() string {
+	if g.Branch == "" {
+		return DefaultGitPagesBranch
+	}
+	return g.Branch
+}
+
+func (g *GitPages) path(name string) string {
+	return path.Join(strings.Trim(g.Dir, "/"), name)
+}
+
+func (g *GitPages) write(tree map[string]gitEntry, files []File) error {
+	for _, f := range files {
+		if err := validName(f.Name); err != nil {
+			return err
+		}
+		if err := g.add(tree, f.Name, f.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GitPages) add(tree map[string]gitEntry, name, content string) error {
+	sha, err := g.git(nil, content, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return err
+	}
+	tree[g.path(name)] = gitEntry{mode: "100644", sha: sha}
+	return nil
+}
+
+// commit applies fn to the files of the branch and commits the result on
+// top of it, regenerating the index page. The ref is only moved if nobody
+// else moved it in the meantime.
+func (g *GitPages) commit(message string, fn func(tree map[string]gitEntry, threads map[string]gitPagesThread) error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ref := "refs/heads/" + g.branch()
+	if head, _ := g.git(nil, "", "symbolic-ref", "-q", "HEAD"); head == ref {
+		return fmt.Errorf("git-pages: branch %s is checked out in %s; publish to a branch that is not checked out", g.branch(), g.repo())
+	}
+
+	parent, _ := g.git(nil, "", "rev-parse", "-q", "--verify", ref+"^{commit}")
+	tree := make(map[string]gitEntry)
+	var parentTree string
+	if parent != "" {
+		out, err := g.git(nil, "", "ls-tree", "-r", "-z", parent)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(out, "\x00") {
+			meta, name, ok := strings.Cut(line, "\t")
+			if !ok {
+				continue
+			}
+			fields := strings.Fields(meta)
+			if len(fields) == 3 {
+				tree[name] = gitEntry{mode: fields[0], sha: fields[2]}
+			}
+		}
+		if parentTree, err = g.git(nil, "", "rev-parse", parent+"^{tree}"); err != nil {
+			return err
+		}
+	}
+
+	threads, err := g.readManifest(tree)
+	if err != nil {
+		return err
+	}
+	if err := fn(tree, threads); err != nil {
+		return err
+	}
+	if err := g.writeIndex(tree, threads); err != nil {
+		return err
+	}
+
+	newTree, err := g.writeTree(tree)
+	if err != nil {
+		return err
+	}
+	if newTree == parentTree {
+		return nil
+	}
+
+	args := []string{"commit-tree", newTree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	commit, err := g.git(g.identity(), "", args...)
+	if err != nil {
+		return err
+	}
+	if _, err := g.git(nil, "", "update-ref", "-m", "claude-coding: "+message, ref, commit, parent); err != nil {
+		return err
+	}
+
+	if g.Remote != "" {
+		if _, err := g.git(nil, "", "push", "-q", g.Remote, ref+":"+ref); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *GitPages) readManifest(tree map[string]gitEntry) (map[string]gitPagesThread, error) {
+	threads := make(map[string]gitPagesThread)
+	entry, ok := tree[g.path(gitPagesManifest)]
+	if !ok {
+		return threads, nil
+	}
+	data, err := g.git(nil, "", "cat-file", "blob", entry.sha)
+	if err != nil {
+		return nil, err
+	}
+	var list []gitPagesThread
+	if err := json.Unmarshal([]byte(data), &list); err != nil {
+		return nil, fmt.Errorf("git-pages: invalid %s: %w", gitPagesManifest, err)
+	}
+	for _, t := range list {
+		threads[t.File] = t
+	}
+	return threads, nil
+}
+
+var gitPagesIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Claude Code Threads</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; max-width: 800px; margin: 40px auto; padding: 0 20px; color: #1f2328; }
+h1 { font-size: 24px; }
+ul { list-style: none; padding: 0; }
+li { display: flex; justify-content: space-between; gap: 16px; padding: 10px 0; border-bottom: 1px solid #d0d7de; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+time { color: #656d76; font-size: 14px; white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>Claude Code Threads</h1>
+<ul>
+{{- range .}}
+<li><a href="{{.File}}">{{.Title}}</a> <time datetime="{{.UpdatedAt.Format "2006-01-02T15:04:05Z07:00"}}">{{.UpdatedAt.Format "2006-01-02 15:04"}}</time></li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+func (g *GitPages) writeIndex(tree map[string]gitEntry, threads map[string]gitPagesThread) error {
+	list := make([]gitPagesThread, 0, len(threads))
+	for _, t := range threads {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].UpdatedAt.Equal(list[j].UpdatedAt) {
+			return list[i].UpdatedAt.After(list[j].UpdatedAt)
+		}
+		return list[i].File < list[j].File
+	})
+
+	manifest, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	var index bytes.Buffer
+	if err := gitPagesIndexTemplate.Execute(&index, list); err != nil {
+		return err
+	}
+	if err := g.add(tree, gitPagesManifest, string(manifest)+"\n"); err != nil {
+		return err
+	}
+	return g.add(tree, gitPagesIndex, index.String())
+}
+
+// writeTree builds a tree object from the entries in a temporary index, so
+// the repository's own index is left alone.
+func (g *GitPages) writeTree(tree map[string]gitEntry) (string, error) {
+	tmp, err := os.MkdirTemp("", "claude-coding-git-pages-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+
+	var info strings.Builder
+	for name, e := range tree {
+		fmt.Fprintf(&info, "%s %s\t%s\x00", e.mode, e.sha, name)
+	}
+	if _, err := g.git(env, info.String(), "update-index", "-z", "--index-info"); err != nil {
+		return "", err
+	}
+	return g.git(env, "", "write-tree")
+}
+
+// identity falls back to a fixed committer when git has none configured,
+// so publishing works on machines that never commit.
+func (g *GitPages) identity() []string {
+	if _, err := g.git(nil, "", "var", "GIT_COMMITTER_IDENT"); err == nil {
+		return nil
+	}
+	return []string{
+		"GIT_AUTHOR_NAME=claude-coding", "GIT_AUTHOR_EMAIL=claude-coding@localhost",
+		"GIT_COMMITTER_NAME=claude-coding", "GIT_COMMITTER_EMAIL=claude-coding@localhost",
+	}
+}
+
+func (g *GitPages) repo() string {
+	if g.Repo == "" {
+		return "."
+	}
+	return g.Repo
+}
+
+func (g *GitPages) git(env []string, stdin string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.repo()}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\n"), nil
+}
+
+func validName(name string) error {
+	if name == "" || name != path.Base(name) || name == gitPagesManifest || name == gitPagesIndex {
+		return fmt.Errorf("invalid file name %q", name)
+	}
+	return nil
+}
+
+func pageTitle(content string) string {
+	if m := titleRe.FindStringSubmatch(content); m != nil {
+		return html.UnescapeString(strings.TrimSpace(m[1]))
+	}
+	return "Claude Code Thread"
+}
@@ -0,0 +1,121 @@
+package publish
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/gist"
+)
+
+type HTTP struct {
+	Endpoint   string
+	PublicURL  string
+	Token      string
+	HTTPClient *http.Client
+}
+
+func (h *HTTP) Publish(files []File) (string, error) {
+	if len(files) == 0 {
+		return "", errNoFiles
+	}
+	for _, f := range files {
+		if err := h.put(f.Name, f.Content); err != nil {
+			return "", err
+		}
+	}
+	return files[0].Name, nil
+}
+
+func (h *HTTP) Update(id string, files []File, previous []string) error {
+	if len(files) == 0 {
+		return errNoFiles
+	}
+	if err := h.put(id, files[0].Content); err != nil {
+		return err
+	}
+	for _, f := range files[1:] {
+		if err := h.put(f.Name, f.Content); err != nil {
+			return err
+		}
+	}
+	for _, name := range Stale(previous, files) {
+		if name != id {
+			if err := h.do(http.MethodDelete, name, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *HTTP) Delete(id string, names []string) error {
+	for _, name := range append([]string{id}, names...) {
+		if err := h.do(http.MethodDelete, name, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *HTTP) URL(id, name string) string {
+	if name == "" {
+		name = id
+	}
+	base := h.PublicURL
+	if base == "" {
+		base = h.Endpoint
+	}
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(name)
+}
+
+func (h *HTTP) put(id, content string) error {
+	return h.do(http.MethodPut, id, content)
+}
+
+func (h *HTTP) do(method, id, content string) error {
+	if h.Endpoint == "" {
+		return fmt.Errorf("http %w: set http.endpoint", ErrNotConfigured)
+	}
+
+	target := strings.TrimSuffix(h.Endpoint, "/") + "/" + url.PathEscape(id)
+	var body io.Reader
+	if method == http.MethodPut {
+		body = strings.NewReader(content)
+	}
+
+	req, err := http.NewRequest(method, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "claude-coding")
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", contentType(id))
+	}
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	client := h.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		if method == http.MethodDelete && resp.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Method: method, URL: target, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data)), RetryAfter: gist.RetryAfter(resp.Header, time.Now())}
+	}
+	return nil
+}
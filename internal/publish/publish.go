@@ -0,0 +1,113 @@
+package publish
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+)
+
+type File struct {
+	Name    string
+	Content string
+}
+
+// Publisher uploads a thread as one or more files; the first file is the
+// page its URL points to. Update replaces the whole set, removing the
+// previous files that are no longer part of it, and Delete removes the
+// share together with the named files.
+type Publisher interface {
+	Publish(files []File) (id string, err error)
+	Update(id string, files []File, previous []string) error
+	Delete(id string, names []string) error
+	URL(id, name string) string
+}
+
+// Linker is implemented by publishers whose threads link to each other by
+// relative URLs rather than by the URLs they are shared with.
+type Linker interface {
+	Link(id, name string) string
+}
+
+// Link returns the URL other threads use to link to the named file.
+func Link(p Publisher, id, name string) string {
+	if l, ok := Unwrap(p).(Linker); ok {
+		return l.Link(id, name)
+	}
+	return p.URL(id, name)
+}
+
+const (
+	BackendGist     = "gist"
+	BackendDir      = "dir"
+	BackendS3       = "s3"
+	BackendHTTP     = "http"
+	BackendGitPages = "git-pages"
+)
+
+var Backends = []string{BackendGist, BackendDir, BackendS3, BackendHTTP, BackendGitPages}
+
+var ErrNotConfigured = errors.New("publisher is not configured")
+
+func ParseBackend(name string) (string, error) {
+	for _, b := range Backends {
+		if name == b {
+			return b, nil
+		}
+	}
+	return "", fmt.Errorf("unknown publisher %q (want one of gist, dir, s3, http, git-pages)", name)
+}
+
+func defaultHTTPClient() *http.Client {
+	return &http.Client{Timeout: 60 * time.Second}
+}
+
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func IsNotFound(err error) bool {
+	return Classify(err) == KindNotFound
+}
+
+// Stale returns the names in previous that are not among files.
+func Stale(previous []string, files []File) []string {
+	current := make(map[string]bool, len(files))
+	for _, f := range files {
+		current[f.Name] = true
+	}
+	var stale []string
+	for _, name := range previous {
+		if name != "" && !current[name] {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+var errNoFiles = errors.New("nothing to publish")
+
+// contentType returns the MIME type an uploaded file is served with.
+func contentType(name string) string {
+	switch path.Ext(name) {
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	case ".json":
+		return "application/json"
+	case ".jsonl":
+		return "application/x-ndjson"
+	}
+	return "text/html; charset=utf-8"
+}
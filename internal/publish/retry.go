@@ -0,0 +1,125 @@
+package publish
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	DefaultAttempts  = 4
+	DefaultBaseDelay = time.Second
+	DefaultMaxDelay  = 30 * time.Second
+	DefaultMaxWait   = time.Minute
+)
+
+// Retry wraps a Publisher and retries operations that fail with a transient
+// error, backing off exponentially with jitter. Rate-limited calls wait for
+// the reset time the server reports, unless that is longer than MaxWait.
+type Retry struct {
+	Publisher
+
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	MaxWait   time.Duration
+	Sleep     func(time.Duration)
+}
+
+func WithRetry(p Publisher) *Retry {
+	return &Retry{
+		Publisher: p,
+		Attempts:  DefaultAttempts,
+		BaseDelay: DefaultBaseDelay,
+		MaxDelay:  DefaultMaxDelay,
+		MaxWait:   DefaultMaxWait,
+	}
+}
+
+// Unwrap returns the publisher behind any retry wrapper.
+func Unwrap(p Publisher) Publisher {
+	if r, ok := p.(*Retry); ok {
+		return Unwrap(r.Publisher)
+	}
+	return p
+}
+
+// Publish retries only failures that cannot have created anything: rate
+// limits and requests that never reached the server. A publish that timed
+// out or got a server error may still have gone through, and retrying it
+// could leave a duplicate share behind.
+func (r *Retry) Publish(files []File) (id string, err error) {
+	err = r.retry(func() error {
+		id, err = r.Publisher.Publish(files)
+		return err
+	}, func(err error) bool {
+		return Classify(err) == KindRateLimit || NotSent(err)
+	})
+	return id, err
+}
+
+func (r *Retry) Update(id string, files []File, previous []string) error {
+	return r.Do(func() error {
+		return r.Publisher.Update(id, files, previous)
+	})
+}
+
+func (r *Retry) Delete(id string, names []string) error {
+	return r.Do(func() error {
+		return r.Publisher.Delete(id, names)
+	})
+}
+
+// Do calls fn until it succeeds, fails with a permanent error or runs out
+// of attempts, and returns its last error.
+func (r *Retry) Do(fn func() error) error {
+	return r.retry(fn, func(err error) bool {
+		return Classify(err).Transient()
+	})
+}
+
+func (r *Retry) retry(fn func() error, retryable func(error) bool) error {
+	attempts := r.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	sleep := r.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= attempts {
+			return err
+		}
+		if !retryable(err) {
+			return err
+		}
+		delay, ok := r.delay(err, attempt)
+		if !ok {
+			return err
+		}
+		sleep(delay)
+	}
+}
+
+func (r *Retry) delay(err error, attempt int) (time.Duration, bool) {
+	backoff := r.BaseDelay << (attempt - 1)
+	if backoff <= 0 || (r.MaxDelay > 0 && backoff > r.MaxDelay) {
+		backoff = r.MaxDelay
+	}
+	// Equal jitter: wait between half and all of the backoff.
+	if backoff > 1 {
+		backoff = backoff/2 + rand.N(backoff/2)
+	}
+
+	if Classify(err) == KindRateLimit {
+		if wait := RetryAfter(err); wait > 0 {
+			if r.MaxWait > 0 && wait > r.MaxWait {
+				return 0, false
+			}
+			return wait + backoff/4, true
+		}
+	}
+	return backoff, true
+}
@@ -0,0 +1,91 @@
+package publish
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// flaky is a publisher whose calls fail with err until it runs out of
+// failures.
+type flaky struct {
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flaky) fail() error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func (f *flaky) Publish(files []File) (string, error) { return "id", f.fail() }
+
+func (f *flaky) Update(id string, files []File, previous []string) error { return f.fail() }
+
+func (f *flaky) Delete(id string, names []string) error { return f.fail() }
+
+func (f *flaky) URL(id, name string) string { return "" }
+
+func TestRetry(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	serverErr := &StatusError{Method: http.MethodPut, StatusCode: http.StatusBadGateway}
+	rateLimit := &StatusError{Method: http.MethodPut, StatusCode: http.StatusTooManyRequests}
+	notFound := &StatusError{Method: http.MethodPut, StatusCode: http.StatusNotFound}
+
+	tests := []struct {
+		name      string
+		err       error
+		publish   int
+		updateDel int
+	}{
+		{"dial error", dialErr, 2, 2},
+		{"rate limit", rateLimit, 2, 2},
+		{"connection reset", readErr, 1, 2},
+		{"server error", serverErr, 1, 2},
+		{"not found", notFound, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := func(op func(r *Retry) error) int {
+				f := &flaky{err: tt.err, failures: 1}
+				r := WithRetry(f)
+				r.Sleep = func(time.Duration) {}
+				op(r)
+				return f.calls
+			}
+
+			if got := calls(func(r *Retry) error { _, err := r.Publish(nil); return err }); got != tt.publish {
+				t.Errorf("Publish made %d calls, want %d", got, tt.publish)
+			}
+			if got := calls(func(r *Retry) error { return r.Update("id", nil, nil) }); got != tt.updateDel {
+				t.Errorf("Update made %d calls, want %d", got, tt.updateDel)
+			}
+			if got := calls(func(r *Retry) error { return r.Delete("id", nil) }); got != tt.updateDel {
+				t.Errorf("Delete made %d calls, want %d", got, tt.updateDel)
+			}
+		})
+	}
+}
+
+func TestNotSent(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	client := server.Client()
+	server.Close()
+
+	_, err := client.Get(server.URL)
+	if !NotSent(err) {
+		t.Errorf("NotSent(%v) = false for a refused connection", err)
+	}
+	if NotSent(&StatusError{StatusCode: http.StatusBadGateway}) {
+		t.Error("NotSent = true for a server response")
+	}
+}
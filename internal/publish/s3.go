@@ -0,0 +1,267 @@
+package publish
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/priyanshujain/claude-coding/internal/gist"
+)
+
+type S3 struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	PublicURL       string
+	HTTPClient      *http.Client
+}
+
+func (s *S3) Publish(files []File) (string, error) {
+	if len(files) == 0 {
+		return "", errNoFiles
+	}
+	id := s.key(files[0].Name)
+	for _, f := range files {
+		if err := s.do(http.MethodPut, siblingKey(id, f.Name), []byte(f.Content)); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+func (s *S3) Update(id string, files []File, previous []string) error {
+	if len(files) == 0 {
+		return errNoFiles
+	}
+	if err := s.do(http.MethodPut, id, []byte(files[0].Content)); err != nil {
+		return err
+	}
+	for _, f := range files[1:] {
+		if err := s.do(http.MethodPut, siblingKey(id, f.Name), []byte(f.Content)); err != nil {
+			return err
+		}
+	}
+	for _, name := range Stale(previous, files) {
+		if key := siblingKey(id, name); key != id {
+			if err := s.do(http.MethodDelete, key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *S3) Delete(id string, names []string) error {
+	if err := s.do(http.MethodDelete, id, nil); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if key := siblingKey(id, name); key != id {
+			if err := s.do(http.MethodDelete, key, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *S3) URL(id, name string) string {
+	key := id
+	if name != "" {
+		key = siblingKey(id, name)
+	}
+	if s.PublicURL != "" {
+		return strings.TrimSuffix(s.PublicURL, "/") + "/" + uriEncode(key, false)
+	}
+	return s.objectURL(key)
+}
+
+// siblingKey stores name next to the object id, so every file of a share
+// stays under the prefix the share was first published with.
+func siblingKey(id, name string) string {
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return id[:i+1] + name
+	}
+	return name
+}
+
+func (s *S3) key(name string) string {
+	prefix := strings.Trim(s.Prefix, "/")
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+func (s *S3) objectURL(key string) string {
+	return strings.TrimSuffix(s.Endpoint, "/") + "/" + uriEncode(s.Bucket, false) + "/" + uriEncode(key, false)
+}
+
+func (s *S3) do(method, key string, body []byte) error {
+	if s.Endpoint == "" || s.Bucket == "" {
+		return fmt.Errorf("s3 %w: set s3.endpoint and s3.bucket", ErrNotConfigured)
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("s3 %w: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", ErrNotConfigured)
+	}
+
+	target := s.objectURL(key)
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", contentType(key))
+	}
+	s.sign(req, body, time.Now().UTC())
+
+	client := s.HTTPClient
+	if client == nil {
+		client = defaultHTTPClient()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &StatusError{Method: method, URL: target, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data)), RetryAfter: gist.RetryAfter(resp.Header, time.Now())}
+	}
+	return nil
+}
+
+// sigV4 holds the intermediate values of a request signature.
+type sigV4 struct {
+	CanonicalRequest string
+	StringToSign     string
+	Signature        string
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req. It
+// signs the host, Content-Type, Content-MD5, Date, Range and every
+// X-Amz-* header of the request.
+func (s *S3) sign(req *http.Request, body []byte, now time.Time) sigV4 {
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		switch {
+		case name == "content-type", name == "content-md5", name == "date", name == "range", strings.HasPrefix(name, "x-amz-"):
+			for i, v := range values {
+				values[i] = strings.Join(strings.Fields(v), " ")
+			}
+			headers[name] = strings.Join(values, ",")
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	var sig sigV4
+	sig.CanonicalRequest = strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	sig.StringToSign = strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(sig.CanonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	sig.Signature = hex.EncodeToString(hmacSHA256(key, sig.StringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKeyID, scope, signedHeaders, sig.Signature))
+	return sig
+}
+
+// canonicalQuery encodes the query parameters sorted by name, as SigV4
+// requires; a parameter without a value keeps its "=".
+func canonicalQuery(query url.Values) string {
+	var params []string
+	for name, values := range query {
+		for _, v := range values {
+			params = append(params, uriEncode(name, true)+"="+uriEncode(v, true))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func uriEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -172,6 +172,186 @@ body {
 .session-nav .nav-next {
   margin-left: auto;
 }
+.session-trail {
+  margin: -8px 0 16px;
+  font-size: 13px;
+  color: #666;
+}
+.session-trail ol {
+  display: flex;
+  flex-wrap: wrap;
+  align-items: center;
+  gap: 6px;
+  list-style: none;
+  margin: 0;
+  padding: 0;
+}
+.session-trail ol li + li::before {
+  content: "›";
+  margin-right: 6px;
+  color: #aaa;
+}
+.session-trail a {
+  color: #2563eb;
+  text-decoration: none;
+}
+.session-trail a:hover {
+  text-decoration: underline;
+}
+.session-trail .trail-current {
+  font-weight: 600;
+  color: #333;
+}
+.session-trail .trail-branches {
+  list-style: none;
+  margin: 6px 0 0;
+  padding-left: 16px;
+  border-left: 2px solid #e5e5e5;
+}
+.session-trail .trail-branches li {
+  padding: 2px 0;
+}
+.session-trail .link-kind {
+  display: inline-block;
+  margin-right: 4px;
+  padding: 0 6px;
+  border-radius: 8px;
+  background: #eef2ff;
+  color: #4f46e5;
+  font-size: 11px;
+}
+.author-note {
+  margin: 0 0 24px;
+  padding: 12px 16px;
+  border-left: 4px solid #f59e0b;
+  border-radius: 4px;
+  background: #fffbeb;
+  font-size: 14px;
+  color: #333;
+}
+.author-note strong {
+  display: block;
+  margin-bottom: 4px;
+  color: #92400e;
+  font-size: 12px;
+  text-transform: uppercase;
+  letter-spacing: 0.04em;
+}
+.author-note p {
+  margin: 4px 0 0;
+}
+.session-index {
+  margin: -8px 0 16px;
+  font-size: 13px;
+  color: #666;
+}
+.session-index summary {
+  cursor: pointer;
+  color: #2563eb;
+}
+.session-index ol {
+  margin: 6px 0 0;
+  padding: 8px 12px 8px 32px;
+  border: 1px solid #e5e5e5;
+  border-radius: 8px;
+  background: #fff;
+}
+.session-index li {
+  padding: 2px 0;
+}
+.session-index a {
+  color: #2563eb;
+  text-decoration: none;
+}
+.session-index a:hover {
+  text-decoration: underline;
+}
+.session-index .index-current {
+  font-weight: 600;
+  color: #333;
+}
+.session-index time {
+  margin-left: 6px;
+  color: #888;
+  font-size: 12px;
+}
+.chain-toc {
+  margin-bottom: 24px;
+  padding: 12px 16px;
+  border: 1px solid #e5e5e5;
+  border-radius: 8px;
+  font-size: 14px;
+}
+.chain-toc h2 {
+  margin: 0 0 8px;
+  font-size: 15px;
+}
+.chain-toc ol {
+  margin: 0;
+  padding-left: 20px;
+}
+.chain-toc li {
+  padding: 2px 0;
+}
+.chain-toc a {
+  color: #2563eb;
+  text-decoration: none;
+}
+.chain-toc a:hover {
+  text-decoration: underline;
+}
+.chain-toc time,
+.chain-divider time {
+  margin-left: 6px;
+  color: #888;
+  font-size: 12px;
+}
+.chain-divider {
+  margin: 32px 0 16px;
+  padding-top: 16px;
+  border-top: 2px solid #e5e5e5;
+}
+.chain-divider:first-child {
+  margin-top: 0;
+}
+.chain-divider .chain-label {
+  color: #888;
+  font-size: 12px;
+  text-transform: uppercase;
+  letter-spacing: 0.04em;
+}
+.chain-divider h2 {
+  margin: 4px 0 0;
+  font-size: 18px;
+}
+.chain-divider time {
+  margin-left: 0;
+}
+.page-nav {
+  display: flex;
+  justify-content: center;
+  align-items: center;
+  gap: 12px;
+  padding: 12px 0;
+  margin-bottom: 16px;
+  font-size: 14px;
+  color: #666;
+}
+.page-nav a {
+  color: #2563eb;
+  text-decoration: none;
+}
+.page-nav a:hover {
+  text-decoration: underline;
+}
+.page-nav .page-list {
+  display: flex;
+  gap: 8px;
+}
+.page-nav .page-current {
+  font-weight: 600;
+  color: #333;
+}
 .command-block .tool-pill {
   background: #f0f0f0;
   border-color: #ddd;
@@ -527,3 +707,94 @@ Prism.highlightAll();
 </html>`
 
 const ClaudeIcon = `<img src="https://upload.wikimedia.org/wikipedia/commons/thumb/b/b0/Claude_AI_symbol.svg/960px-Claude_AI_symbol.svg.png" alt="Claude" style="width:20px;height:20px;">`
+
+const EncryptedTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Encrypted Claude Code Thread</title>
+<style>
+* { box-sizing: border-box; margin: 0; padding: 0; }
+body {
+  font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+  background: #fff;
+  color: #1a1a1a;
+  line-height: 1.7;
+  font-size: 15px;
+}
+.container { max-width: 420px; margin: 0 auto; padding: 80px 20px; text-align: center; }
+h1 { font-size: 1.4rem; font-weight: 600; margin-bottom: 8px; }
+p { font-size: 0.875rem; color: #666; margin-bottom: 24px; }
+form { display: flex; gap: 8px; }
+input {
+  flex: 1;
+  padding: 8px 12px;
+  border: 1px solid #e0e0e0;
+  border-radius: 8px;
+  font-size: 14px;
+}
+button {
+  padding: 8px 16px;
+  background: #1a1a1a;
+  color: #fff;
+  border: none;
+  border-radius: 8px;
+  font-size: 14px;
+  cursor: pointer;
+}
+button:disabled { opacity: 0.6; cursor: default; }
+.error { margin-top: 16px; font-size: 13px; color: #82071e; min-height: 1em; }
+</style>
+</head>
+<body>
+<div class="container">
+<h1>This thread is encrypted</h1>
+<p>Enter the passphrase you were given to read it.</p>
+<form id="unlock">
+<input id="passphrase" type="password" autocomplete="current-password" placeholder="Passphrase" autofocus>
+<button type="submit">Unlock</button>
+</form>
+<div class="error" id="error"></div>
+</div>
+<script type="application/json" id="payload">PAYLOAD_PLACEHOLDER</script>
+<script>
+const payload = JSON.parse(document.getElementById('payload').textContent);
+const bytes = s => Uint8Array.from(atob(s), c => c.charCodeAt(0));
+
+async function decrypt(passphrase) {
+  const baseKey = await crypto.subtle.importKey('raw', new TextEncoder().encode(passphrase), payload.kdf, false, ['deriveKey']);
+  const key = await crypto.subtle.deriveKey(
+    { name: payload.kdf, salt: bytes(payload.salt), iterations: payload.iterations, hash: payload.hash },
+    baseKey,
+    { name: 'AES-GCM', length: 256 },
+    false,
+    ['decrypt']
+  );
+  const plain = await crypto.subtle.decrypt({ name: 'AES-GCM', iv: bytes(payload.iv) }, key, bytes(payload.data));
+  return new TextDecoder().decode(plain);
+}
+
+document.getElementById('unlock').addEventListener('submit', async e => {
+  e.preventDefault();
+  const button = e.target.querySelector('button');
+  const error = document.getElementById('error');
+  if (!window.crypto || !crypto.subtle) {
+    error.textContent = 'Your browser cannot decrypt this page (WebCrypto is unavailable).';
+    return;
+  }
+  button.disabled = true;
+  error.textContent = '';
+  try {
+    const html = await decrypt(document.getElementById('passphrase').value);
+    document.open();
+    document.write(html);
+    document.close();
+  } catch (err) {
+    error.textContent = 'Wrong passphrase.';
+    button.disabled = false;
+  }
+});
+</script>
+</body>
+</html>`
@@ -7,6 +7,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+
+	"github.com/priyanshujain/claude-coding/internal/index"
 )
 
 type HookInput struct {
@@ -32,4 +34,8 @@ func main() {
 	hash := md5.Sum([]byte(hookInput.Cwd))
 	prevSessionFile := filepath.Join(os.TempDir(), "claude-prev-session-"+hex.EncodeToString(hash[:]))
 	os.WriteFile(prevSessionFile, []byte(hookInput.SessionID), 0644)
+
+	// The session's messages are final now, so this is when its counts
+	// and time range are recorded.
+	index.Update(hookInput.Cwd, hookInput.SessionID)
 }
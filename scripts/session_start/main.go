@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
@@ -11,12 +12,14 @@ import (
 	"time"
 
 	"github.com/priyanshujain/claude-coding/generic/metadata"
+	"github.com/priyanshujain/claude-coding/internal/index"
 )
 
 type HookInput struct {
-	SessionID string `json:"session_id"`
-	Source    string `json:"source"`
-	Cwd       string `json:"cwd"`
+	SessionID      string `json:"session_id"`
+	Source         string `json:"source"`
+	Cwd            string `json:"cwd"`
+	TranscriptPath string `json:"transcript_path"`
 }
 
 func readPrevSessionWithRetry(filePath string, maxRetries int, delay time.Duration) string {
@@ -32,6 +35,35 @@ func readPrevSessionWithRetry(filePath string, maxRetries int, delay time.Durati
 	return ""
 }
 
+// resumedFrom returns the session a resumed session was started from. The
+// transcript of a resumed session begins with the messages of the session
+// it resumed, which still carry that session's ID.
+func resumedFrom(transcriptPath, sessionID string) string {
+	if transcriptPath == "" {
+		return ""
+	}
+	f, err := os.Open(transcriptPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		var entry struct {
+			SessionID string `json:"sessionId"`
+		}
+		if json.Unmarshal(scanner.Bytes(), &entry) != nil {
+			continue
+		}
+		if entry.SessionID != "" && entry.SessionID != sessionID {
+			return entry.SessionID
+		}
+	}
+	return ""
+}
+
 func main() {
 	input, err := io.ReadAll(os.Stdin)
 	if err != nil {
@@ -58,14 +90,26 @@ func main() {
 		}
 	}
 
+	kind := metadata.LinkClear
+	if hookInput.Source == "resume" {
+		prevSessionID = resumedFrom(hookInput.TranscriptPath, hookInput.SessionID)
+		kind = metadata.LinkResume
+	}
+
 	metadata.WithLock(hookInput.Cwd, func(m *metadata.Metadata) error {
 		if prevSessionID != "" && prevSessionID != hookInput.SessionID {
-			m.LinkSession(prevSessionID, hookInput.SessionID)
+			// Resuming a session that was already continued branches off
+			// its history.
+			if kind == metadata.LinkResume && len(m.GetChildren(prevSessionID)) > 0 {
+				kind = metadata.LinkFork
+			}
+			m.LinkSession(prevSessionID, hookInput.SessionID, kind)
 		} else {
-			m.LinkSession("", hookInput.SessionID)
+			m.LinkSession("", hookInput.SessionID, "")
 		}
 		return nil
 	})
+	index.Update(hookInput.Cwd, hookInput.SessionID)
 
 	envFile := os.Getenv("CLAUDE_ENV_FILE")
 	if envFile != "" {